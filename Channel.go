@@ -1,20 +1,17 @@
 package discord
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 )
 
 type ChannelType int
 
-var rateLimiter chan struct{}
-var dummy struct{}
-
 // Channel types
 const (
 	ChannelTypeGuildText = ChannelType(iota)
@@ -26,94 +23,69 @@ const (
 	ChannelTypeGuildStore
 )
 
-func init() {
-	rateLimiter = make(chan struct{}, 5)
-	go func() {
-		for {
-			select {
-			case <-rateLimiter:
-			default:
-				time.Sleep(2 * time.Second)
-			}
-		}
-	}()
-}
-
 // Channel is the Go representation of Channel in Discord's API.
 type Channel struct {
-	ID                   string
-	Type                 ChannelType
-	GuildID              string
-	Position             int
-	PermissionOverwrites []Overwrite
-	Name                 string
-	Topic                string
-	NSFW                 bool
-	LastMessageID        string
-	Bitrate              int
-	UserLimit            int
-	RateLimitPerUser     int
-	Recipients           []User
-	Icon                 string
-	OwnerID              string
-	ApplicationID        string
-	ParentID             string
-	LastPinTimestamp     time.Time
+	ID                   string      `json:"id"`
+	Type                 ChannelType `json:"type"`
+	GuildID              string      `json:"guild_id"`
+	Position             int         `json:"position"`
+	PermissionOverwrites []Overwrite `json:"permission_overwrites"`
+	Name                 string      `json:"name"`
+	Topic                string      `json:"topic"`
+	NSFW                 bool        `json:"nsfw"`
+	LastMessageID        string      `json:"last_message_id"`
+	Bitrate              int         `json:"bitrate"`
+	UserLimit            int         `json:"user_limit"`
+	RateLimitPerUser     int         `json:"rate_limit_per_user"`
+	Recipients           []User      `json:"recipients"`
+	Icon                 string      `json:"icon"`
+	OwnerID              string      `json:"owner_id"`
+	ApplicationID        string      `json:"application_id"`
+	ParentID             string      `json:"parent_id"`
+	LastPinTimestamp     time.Time   `json:"last_pin_timestamp"`
 	client               *Client
 }
 
 // Sends a message to the channel
 func (c *Channel) Send(message string) error {
-	if message == "" {
-		return errors.New("cannot send empty message")
-	}
+	_, err := c.SendMessage(context.Background(), MessageSend{Content: message})
+	return err
+}
 
-	req, err := http.NewRequest(http.MethodPost, "https://discordapp.com/api/v6/channels/"+c.ID+"/messages", strings.NewReader(`{"content":`+strconv.Quote(message)+`}`))
-	if err != nil {
-		return err
+// SendMessage sends a message to the channel and returns it as created by Discord.
+// Files are read as they are uploaded.
+func (c *Channel) SendMessage(ctx context.Context, message MessageSend) (m Message, err error) {
+	if message.Content == "" && len(message.Embeds) == 0 && len(message.Files) == 0 {
+		return m, errors.New("cannot send empty message")
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bot "+c.client.Token)
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+	var body interface{} = message
+	if c.client.apiVersion < 8 {
+		if body, err = message.v6(); err != nil {
+			return
+		}
 	}
 
-	rateLimiter <- dummy
-
-	return resp.Body.Close()
+	path := "/channels/" + c.ID + "/messages"
+	if len(message.Files) > 0 {
+		err = c.client.requestFiles(ctx, http.MethodPost, path, body, message.Files, &m)
+	} else {
+		err = c.client.request(ctx, http.MethodPost, path, body, &m)
+	}
+	return
 }
 
 // Messages returns the last limit messages in the channel
 func (c *Channel) Messages(limit int) ([]Message, error) {
-	url := "https://discordapp.com/api/v6/channels/" + c.ID + "/messages"
+	path := "/channels/" + c.ID + "/messages"
 	if limit > 1 {
-		url += "?limit=" + strconv.Itoa(limit)
-	}
-	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", "Bot "+c.client.Token)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+		path += "?limit=" + strconv.Itoa(limit)
 	}
 
 	var messages []Message
-
-	if err = json.Unmarshal(b, &messages); err != nil {
+	if err := c.client.request(context.Background(), http.MethodGet, path, nil, &messages); err != nil {
 		return nil, err
 	}
-
 	return messages, nil
 }
 
@@ -135,68 +107,186 @@ func (store ChannelStore) Add(channel Channel) {
 
 // Permission Overwrite in Message
 type Overwrite struct {
-	ID    string
-	Type  string
-	Allow int
-	Deny  int
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Allow int    `json:"allow"`
+	Deny  int    `json:"deny"`
 }
 
 // Message is the Go representation of Message in Discord's API.
 type Message struct {
-	ID              string
-	ChannelID       string `mapstructure:"channel_id"`
-	GuildID         string `mapstructure:"guild_id"`
-	Author          User
-	Content         string
-	TTS             bool
-	MentionEveryone bool `mapstructure:"mention_everyone"`
-	Mentions        []User
-	MentionRoles    []Role `mapstructure:"mention_roles"`
-	Attachments     []Attachment
-	Embeds          []Embed
-	Reactions       []Reaction
-	Nonce           string
-	Pinned          bool
-	WebhookID       string `mapstructure:"webhook_id"`
-	Type            int // TODO Add MessageType type
-	Flags           int // Add MessageFlag type
+	ID               string            `json:"id"`
+	ChannelID        string            `json:"channel_id"`
+	GuildID          string            `json:"guild_id"`
+	Author           User              `json:"author"`
+	Member           *GuildMember      `json:"member"`
+	Content          string            `json:"content"`
+	Timestamp        time.Time         `json:"timestamp"`
+	EditedTimestamp  *time.Time        `json:"edited_timestamp"`
+	TTS              bool              `json:"tts"`
+	MentionEveryone  bool              `json:"mention_everyone"`
+	Mentions         []User            `json:"mentions"`
+	MentionRoles     []string          `json:"mention_roles"` // Role IDs
+	Attachments      []Attachment      `json:"attachments"`
+	Embeds           []Embed           `json:"embeds"`
+	Reactions        []Reaction        `json:"reactions"`
+	Nonce            Nonce             `json:"nonce"`
+	Pinned           bool              `json:"pinned"`
+	WebhookID        string            `json:"webhook_id"`
+	Type             int               `json:"type"`  // TODO Add MessageType type
+	Flags            int               `json:"flags"` // Add MessageFlag type
+	MessageReference *MessageReference `json:"message_reference"`
+}
+
+// Nonce is the nonce a message was sent with. Discord sends it back as it was sent, which
+// may be as a string or an integer, so a Nonce can be unmarshalled from either.
+type Nonce string
+
+// UnmarshalJSON accepts a JSON string or integer.
+func (n *Nonce) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] != '"' && string(b) != "null" {
+		var i json.Number
+		if err := json.Unmarshal(b, &i); err != nil {
+			return err
+		}
+		*n = Nonce(i)
+		return nil
+	}
+	return json.Unmarshal(b, (*string)(n))
+}
+
+// MessageSend is a message to send with Channel.SendMessage.
+type MessageSend struct {
+	Content         string           `json:"content,omitempty"`
+	Embeds          []Embed          `json:"embeds,omitempty"`
+	TTS             bool             `json:"tts,omitempty"`
+	Nonce           string           `json:"nonce,omitempty"`
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+	// MessageReference makes the message a reply to the message it refers to.
+	MessageReference *MessageReference `json:"message_reference,omitempty"`
+	Files            []File            `json:"-"` // Files to attach to the message
+}
+
+// File is a file to upload as an attachment.
+type File struct {
+	Name        string
+	ContentType string    // The file's MIME type; the default is application/octet-stream
+	Reader      io.Reader // The file's content, which is required
+}
+
+// messageSendV6 is a MessageSend for API versions before 8, which take a single embed
+type messageSendV6 struct {
+	MessageSend
+	Embed *Embed `json:"embed,omitempty"`
+}
+
+// v6 returns the message in the form API versions before 8 take
+func (message MessageSend) v6() (messageSendV6, error) {
+	if len(message.Embeds) > 1 {
+		return messageSendV6{}, errors.New("discord: API versions before 8 only send one embed per message")
+	}
+
+	m := messageSendV6{MessageSend: message}
+	if len(message.Embeds) == 1 {
+		m.Embed = &message.Embeds[0]
+	}
+	m.Embeds = nil
+	return m, nil
+}
+
+// AllowedMentionType is a kind of mention in the content of a message.
+type AllowedMentionType string
+
+// Allowed mention types
+const (
+	AllowRoleMentions     AllowedMentionType = "roles"
+	AllowUserMentions     AllowedMentionType = "users"
+	AllowEveryoneMentions AllowedMentionType = "everyone"
+)
+
+// AllowedMentions limits who a sent message notifies. The zero value notifies no one.
+type AllowedMentions struct {
+	Parse       []AllowedMentionType `json:"parse,omitempty"` // Kinds of mention to notify for
+	Roles       []string             `json:"roles,omitempty"` // IDs of roles to notify
+	Users       []string             `json:"users,omitempty"` // IDs of users to notify
+	RepliedUser bool                 `json:"replied_user,omitempty"`
+}
+
+// MessageReference refers to another message, such as the message a reply is to.
+type MessageReference struct {
+	MessageID string `json:"message_id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	GuildID   string `json:"guild_id,omitempty"`
 }
 
 // Attachment is the Go representation of Attachment in Discord's API.
 type Attachment struct {
-	ID       string
-	Filename string
-	Size     int
-	URL      string
-	ProxyURL string
-	Height   *int
-	Width    *int
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	Size        int    `json:"size"`
+	URL         string `json:"url"`
+	ProxyURL    string `json:"proxy_url"`
+	ContentType string `json:"content_type"`
+	Height      *int   `json:"height"`
+	Width       *int   `json:"width"`
 }
 
 // Embed is the Go representation of Embed in Discord's API.
 type Embed struct {
-	Title       *string
-	Type        *string
-	Description *string
-	URL         *string
-	Timestamp   *time.Time
-	Color       *int
+	Title       *string      `json:"title,omitempty"`
+	Type        *string      `json:"type,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	URL         *string      `json:"url,omitempty"`
+	Timestamp   *time.Time   `json:"timestamp,omitempty"`
+	Color       *int         `json:"color,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+	Image       *EmbedImage  `json:"image,omitempty"`
+	Thumbnail   *EmbedImage  `json:"thumbnail,omitempty"`
+	Author      *EmbedAuthor `json:"author,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+}
+
+// EmbedFooter is the Go representation of Embed Footer in Discord's API.
+type EmbedFooter struct {
+	Text    string `json:"text"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
+// EmbedImage is the Go representation of Embed Image and Embed Thumbnail in Discord's API.
+type EmbedImage struct {
+	URL    string `json:"url"`
+	Height int    `json:"height,omitempty"`
+	Width  int    `json:"width,omitempty"`
+}
+
+// EmbedAuthor is the Go representation of Embed Author in Discord's API.
+type EmbedAuthor struct {
+	Name    string `json:"name,omitempty"`
+	URL     string `json:"url,omitempty"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
+// EmbedField is the Go representation of Embed Field in Discord's API.
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
 }
 
 // Emoji is the Go representation of Emoji in Discord's API.
 type Emoji struct {
-	ID            string
-	Name          string
-	Roles         []Role
-	User          User
-	RequireColons bool
-	Managed       bool
-	Animated      bool
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Roles         []string `json:"roles"` // Role IDs
+	User          User     `json:"user"`
+	RequireColons bool     `json:"require_colons"`
+	Managed       bool     `json:"managed"`
+	Animated      bool     `json:"animated"`
 }
 
 // Reaction is the Go representation of Reaction in Discord's API.
 type Reaction struct {
-	Count int
-	Me    bool
-	Emoji Emoji
+	Count int   `json:"count"`
+	Me    bool  `json:"me"`
+	Emoji Emoji `json:"emoji"`
 }
@@ -1,14 +1,14 @@
 package discord
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/websocket"
 	"go/build"
-	"io"
-	"io/ioutil"
-	"net"
+	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -92,8 +92,8 @@ type Activity struct {
 	Details       string             `json:"details"`
 	State         string             `json:"state"`
 	Party         ActivityParty      `json:"party"`
-	Assets        []ActivityAsset    `json:"assets"`
-	Secrets       []ActivitySecret   `json:"secrets"`
+	Assets        ActivityAsset      `json:"assets"`
+	Secrets       ActivitySecret     `json:"secrets"`
 	Instance      bool               `json:"instance"`
 	Flags         ActivityFlag       `json:"flags"`
 }
@@ -117,32 +117,131 @@ type Presence struct {
 type GuildMember struct {
 	User         `json:"user"`
 	Nickname     string    `json:"nick"`
-	Roles        []Role    `json:"roles"`
+	Roles        []string  `json:"roles"` // Role IDs
 	JoinedAt     time.Time `json:"joined_at"`
 	PremiumSince time.Time `json:"premium_since"`
 	Deaf         bool      `json:"deaf"`
 	Mute         bool      `json:"mute"`
 }
 
+const defaultGatewayURL = "wss://gateway.discord.gg"
+
+// errReconnect signals that the gateway connection must be re-established
+var errReconnect = errors.New("discord: gateway reconnect required")
+
+// Bounds for the wait between reconnect attempts
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 2 * time.Minute
+)
+
+// invalidSessionWait returns how long to wait before identifying again after an invalid
+// session. Discord asks for a random wait of 1 to 5 seconds.
+var invalidSessionWait = func() time.Duration {
+	return time.Second + time.Duration(rand.Int63n(int64(4*time.Second)))
+}
+
+// How long Close waits for the close frame to be written
+const closeTimeout = 5 * time.Second
+
+// Number of heartbeat round trip times kept by Client.LatencyHistory
+const latencyHistorySize = 20
+
 // Client interacts with the Discord API.
 type Client struct {
 	User
-	ws                *websocket.Conn
-	heartbeatInterval int
-	sequence          int
-	sessionID         string
-	Token             string
-	handlers          map[GatewayEventType]EventHandler
-	channelStore      ChannelStore
+	gatewayURL     string
+	shard          *[2]int
+	beforeIdentify func(ctx context.Context) error
+	ws             *websocket.Conn
+	sequence       int
+	sessionID      string
+	Token          string
+	intents        Intents
+	handlerIntents bool
+	compress       bool
+	codec          codec
+	presence       *statusUpdateData
+	requestsMu     sync.Mutex
+	nonce          int
+	memberRequests map[string]*memberRequest
+	inflater       *inflater
+	handlers       *handlerSet
+	eventBuffer    int
+	watchersMu     sync.Mutex
+	watchers       map[*watcher]bool
+	overflow       OverflowPolicy
+	dispatcher     *dispatcher
+	channelStore   ChannelStore
+	apiURL         string
+	apiVersion     int
+	httpClient     *http.Client
+	userAgent      string
+	authScheme     AuthScheme
+	rateLimits     *rateLimiter
+	heartbeatMu    sync.Mutex
+	heartbeatAcked bool
+	lastHeartbeat  time.Time
+	latencies      []time.Duration
+	connMu         sync.Mutex
+	writer         *gatewayWriter
+	stopConn       context.CancelFunc
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithIntents makes the client identify with intents, so it only receives the events they select.
+func WithIntents(intents Intents) ClientOption {
+	return func(c *Client) {
+		c.intents = intents
+	}
+}
+
+// WithHandlerIntents makes the client identify with the intents needed by its handlers
+// at the time it connects, in addition to any set by WithIntents.
+func WithHandlerIntents() ClientOption {
+	return func(c *Client) {
+		c.handlerIntents = true
+	}
+}
+
+// WithCompression makes the client ask Discord to compress the gateway connection with
+// zlib-stream, which greatly reduces the size of large payloads such as GUILD_CREATE.
+func WithCompression() ClientOption {
+	return func(c *Client) {
+		c.compress = true
+	}
 }
 
 // Creates a new Discord Client.
-func NewClient(token string) *Client {
-	return &Client{
-		Token:        token,
-		handlers:     map[GatewayEventType]EventHandler{},
-		channelStore: ChannelStore{},
+func NewClient(token string, options ...ClientOption) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		Token:          token,
+		gatewayURL:     defaultGatewayURL,
+		codec:          jsonCodec{},
+		memberRequests: map[string]*memberRequest{},
+		handlers:       newHandlerSet(),
+		watchers:       map[*watcher]bool{},
+		dispatcher:     &dispatcher{},
+		channelStore:   ChannelStore{},
+		rateLimits:     newRateLimiter(),
+		apiURL:         defaultAPIURL,
+		apiVersion:     defaultAPIVersion,
+		httpClient:     http.DefaultClient,
+		userAgent:      defaultUserAgent,
+		authScheme:     AuthBot,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	for _, option := range options {
+		option(c)
 	}
+	return c
 }
 
 //func setField(data interface{}, name string, value interface{}) error {
@@ -167,23 +266,92 @@ func NewClient(token string) *Client {
 //	return nil
 //}
 
-// Send heartbeats forever
-func (c *Client) heartbeat() {
-	ticker := time.NewTicker(time.Duration(c.heartbeatInterval) * time.Millisecond)
+// Send heartbeats through w until ctx is done or writing fails. If the previous heartbeat
+// was never acknowledged the connection is a zombie, so it is closed to force a reconnect.
+func (c *Client) heartbeat(ctx context.Context, w *gatewayWriter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			packet := gatewayOp{Op: 1}
-			if c.sequence != 0 {
-				packet.D = c.sequence
-			}
-			_ = c.ws.WriteJSON(&packet)
+		}
+
+		// Only these heartbeats are timed. One requested by Discord may be sent while
+		// another is unacknowledged, and ACKs don't say which heartbeat they are for.
+		c.heartbeatMu.Lock()
+		acked := c.heartbeatAcked
+		c.heartbeatAcked = false
+		c.lastHeartbeat = time.Now()
+		c.heartbeatMu.Unlock()
+
+		if !acked {
+			_ = w.ws.Close()
+			return
+		}
+
+		if err := c.sendHeartbeat(w); err != nil {
+			return
+		}
+	}
+}
+
+// sendHeartbeat writes a single heartbeat through w
+func (c *Client) sendHeartbeat(w *gatewayWriter) error {
+	packet := gatewayCommand{Op: opHeartbeat}
+
+	c.heartbeatMu.Lock()
+	if c.sequence != 0 {
+		packet.D = c.sequence
+	}
+	c.heartbeatMu.Unlock()
+
+	return w.sendOp(c.clientContext(), packet, true)
+}
+
+// ackHeartbeat records the acknowledgement of the last heartbeat and its round trip time
+func (c *Client) ackHeartbeat() {
+	c.heartbeatMu.Lock()
+	defer c.heartbeatMu.Unlock()
+
+	if !c.heartbeatAcked && !c.lastHeartbeat.IsZero() {
+		c.latencies = append(c.latencies, time.Since(c.lastHeartbeat))
+		if len(c.latencies) > latencyHistorySize {
+			c.latencies = c.latencies[len(c.latencies)-latencyHistorySize:]
 		}
 	}
+	c.heartbeatAcked = true
+}
+
+// Latency returns the round trip time of the most recently acknowledged heartbeat.
+// It returns 0 if no heartbeat has been acknowledged yet.
+func (c *Client) Latency() time.Duration {
+	c.heartbeatMu.Lock()
+	defer c.heartbeatMu.Unlock()
+
+	if len(c.latencies) == 0 {
+		return 0
+	}
+	return c.latencies[len(c.latencies)-1]
+}
+
+// LatencyHistory returns the round trip times of recent heartbeats, oldest first.
+func (c *Client) LatencyHistory() []time.Duration {
+	c.heartbeatMu.Lock()
+	defer c.heartbeatMu.Unlock()
+
+	return append([]time.Duration(nil), c.latencies...)
 }
 
-// Identify with the discord Gateway
-func (c *Client) identify() error {
+// Identify with the discord Gateway, once c.beforeIdentify allows it
+func (c *Client) identify(ctx context.Context) error {
+	if c.beforeIdentify != nil {
+		if err := c.beforeIdentify(ctx); err != nil {
+			return err
+		}
+	}
+
 	data := identifyData{
 		Token: c.Token,
 		Properties: struct {
@@ -195,14 +363,20 @@ func (c *Client) identify() error {
 			Browser: "discord-go",
 			Device:  "discord-go",
 		},
+		Intents:  c.intents,
+		Shard:    c.shard,
+		Presence: c.presence,
+	}
+	if c.handlerIntents {
+		data.Intents |= c.HandlerIntents()
 	}
 
-	packet := gatewayOp{
-		Op: 2,
+	packet := gatewayCommand{
+		Op: opIdentify,
 		D:  data,
 	}
 
-	return c.ws.WriteJSON(packet)
+	return c.send(ctx, packet)
 }
 
 // Resume session if disconnected
@@ -213,82 +387,365 @@ func (c *Client) resume() error {
 		Sequence:  c.sequence,
 	}
 
-	packet := gatewayOp{
-		Op: 6,
+	packet := gatewayCommand{
+		Op: opResume,
 		D:  data,
 	}
 
-	return c.ws.WriteJSON(packet)
+	return c.send(c.clientContext(), packet)
+}
+
+// send writes a payload to the current gateway connection
+func (c *Client) send(ctx context.Context, cmd gatewayCommand) error {
+	c.connMu.Lock()
+	w := c.writer
+	c.connMu.Unlock()
+
+	if w == nil {
+		return errWriterStopped
+	}
+	return w.sendOp(ctx, cmd, false)
 }
 
 // Connect connects the client to a Discord Gateway.
-func (c *Client) Connect() (err error) {
-	c.ws, _, err = websocket.DefaultDialer.Dial("wss://gateway.discord.gg/?v=6&encoding=json", nil)
+func (c *Client) Connect() error {
+	return c.ConnectContext(context.Background())
+}
+
+// ConnectContext connects the client to a Discord Gateway. The context only bounds the
+// connection attempt; the connection itself lasts until the client is closed. A closed
+// client can be connected again.
+func (c *Client) ConnectContext(ctx context.Context) error {
+	c.connMu.Lock()
+	if c.ctx.Err() != nil {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+		c.dispatcher.start()
+	}
+	c.connMu.Unlock()
+
+	return c.connect(ctx)
+}
+
+// connect dials the gateway and resumes the current session, or identifies if there is none.
+func (c *Client) connect(ctx context.Context) error {
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, c.dialURL(), nil)
 	if err != nil {
 		return err
 	}
 
-	var op gatewayOp
-	if err := c.ws.ReadJSON(&op); err != nil {
+	c.inflater = nil
+	if c.compress {
+		c.inflater = &inflater{}
+	}
+
+	c.connMu.Lock()
+	c.ws = ws
+	c.connMu.Unlock()
+
+	// Closing the connection unblocks the handshake if ctx ends first
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = ws.Close()
+		case <-done:
+		}
+	}()
+
+	if err = c.handshake(ctx); err != nil {
+		c.disconnect()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return err
 	}
+	return nil
+}
 
-	c.heartbeatInterval = int(op.D.(map[string]interface{})["heartbeat_interval"].(float64))
+// dialURL returns the URL to connect to the gateway with
+func (c *Client) dialURL() string {
+	u := c.gatewayURL + "/?v=6&encoding=" + string(c.codec.encoding())
+	if c.compress {
+		u += "&compress=zlib-stream"
+	}
+	return u
+}
 
-	go c.heartbeat()
+// disconnect stops the current connection's goroutines and closes it
+func (c *Client) disconnect() {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
 
-	if c.sequence == 0 {
-		err = c.identify()
-	} else {
-		err = c.resume()
+	if c.stopConn != nil {
+		c.stopConn()
 	}
+	if c.ws != nil {
+		_ = c.ws.Close()
+	}
+	c.writer = nil
+}
+
+// handshake waits for Hello, starts heartbeating and then identifies or resumes. ctx
+// bounds the wait to identify.
+func (c *Client) handshake(ctx context.Context) error {
+	op, err := c.readOp()
 	if err != nil {
 		return err
 	}
 
-	var data dispatchData
-	err = c.ws.ReadJSON(&data)
+	var hello helloData
+	if op.Op != opHello || json.Unmarshal(op.D, &hello) != nil {
+		return errors.New("expected Hello from gateway")
+	}
+	interval := time.Duration(hello.HeartbeatInterval) * time.Millisecond
+
+	c.heartbeatMu.Lock()
+	c.heartbeatAcked = true
+	c.heartbeatMu.Unlock()
+
+	// The connection's goroutines are stopped by disconnect rather than by closing the
+	// client, so Close can still write a close frame
+	connCtx, stop := context.WithCancel(context.Background())
+	w := newGatewayWriter(c.ws, c.codec)
+
+	c.connMu.Lock()
+	c.writer = w
+	c.stopConn = stop
+	c.connMu.Unlock()
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		w.run(connCtx)
+	}()
+	go func() {
+		defer c.wg.Done()
+		c.heartbeat(connCtx, w, interval)
+	}()
+
+	if c.sessionID == "" {
+		err = c.identify(ctx)
+	} else {
+		err = c.resume()
+	}
 	if err != nil {
 		return err
 	}
 
-	c.sessionID = data.D.SessionID
-	c.User = data.D.User
-
-	return nil
+	return c.awaitSession()
 }
 
-// handle chooses executes the handler for op
-func handle(op GatewayEventType, c *Client, data map[string]interface{}) error {
-	if v, ok := c.handlers[op]; ok {
-		if err := v.Handle(c, data); err != nil {
+// awaitSession processes payloads from the gateway until the session is ready or resumed.
+// Events replayed by a resume are passed to their handlers.
+func (c *Client) awaitSession() error {
+	for {
+		op, err := c.readOp()
+		if err != nil {
 			return err
 		}
+
+		if err = c.process(op); err != nil {
+			return err
+		}
+
+		if op.Op == opDispatch && (op.T == GatewayReady || op.T == GatewayResumed) {
+			return nil
+		}
 	}
-	return nil
 }
 
-// Listen is a blocking function that will begin listening for Discord Gateway events.
-func (c *Client) Listen() (err error) {
+// readOp reads the next payload from the gateway
+func (c *Client) readOp() (op gatewayOp, err error) {
+	var b []byte
 	for {
-		var op gatewayOp
-		if err = c.ws.ReadJSON(&op); err != nil {
-			if e := err.(*net.OpError); e.Op == "read" {
-				return nil
+		if _, b, err = c.ws.ReadMessage(); err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				gatewayErr := newGatewayCloseError(closeErr)
+				if gatewayErr.sessionInvalid() {
+					c.resetSession()
+				}
+				err = gatewayErr
 			}
+			return
+		}
+		if c.inflater == nil {
+			break
+		}
+
+		// A compressed payload may span several messages
+		if b, err = c.inflater.inflate(b); err != nil {
+			return
+		}
+		if b != nil {
+			break
+		}
+	}
+
+	err = c.codec.decode(b, &op)
+	return
+}
+
+// process acts on a single gateway payload. It returns errReconnect if the connection
+// must be re-established.
+func (c *Client) process(op gatewayOp) error {
+	switch op.Op {
+	case opDispatch:
+		c.heartbeatMu.Lock()
+		c.sequence = op.S
+		c.heartbeatMu.Unlock()
+
+		if op.T == GatewayReady {
+			var ready Ready
+			if err := json.Unmarshal(op.D, &ready); err != nil {
+				return err
+			}
+			c.sessionID = ready.SessionID
+			c.User = ready.User
+		}
+
+		if op.T == GatewayGuildMembersChunk {
+			c.receiveChunk(op.D)
+		}
+
+		c.watch(op.T, op.D)
+		c.dispatcher.dispatch(c, op.T, op.D)
+	case opHeartbeat:
+		c.connMu.Lock()
+		w := c.writer
+		c.connMu.Unlock()
+
+		if w == nil || c.sendHeartbeat(w) != nil {
+			return errReconnect
+		}
+	case opReconnect:
+		return errReconnect
+	case opInvalidSession:
+		var resumable bool
+		if _ = json.Unmarshal(op.D, &resumable); !resumable {
+			c.resetSession()
+		}
+
+		select {
+		case <-c.clientContext().Done():
+			return nil
+		case <-time.After(invalidSessionWait()):
+		}
+
+		var err error
+		if c.sessionID == "" {
+			err = c.identify(c.clientContext())
+		} else {
+			err = c.resume()
+		}
+		if err != nil {
+			return errReconnect
+		}
+	case opHeartbeatACK:
+		c.ackHeartbeat()
+	}
+	return nil
+}
+
+// reconnect re-establishes a dropped gateway connection, waiting exponentially longer
+// between failed attempts. It returns an error if the client was closed before reconnecting,
+// or if Discord closed the connection for a reason reconnecting cannot fix.
+func (c *Client) reconnect() error {
+	c.disconnect()
+
+	ctx := c.clientContext()
+	delay := minReconnectDelay
+	for {
+		err := c.connect(ctx)
+		if err == nil {
+			return nil
+		}
+		if isFatal(err) {
 			return err
 		}
 
-		if op.Op != 0 {
-			continue
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
 		}
 
-		data, ok := op.D.(map[string]interface{})
-		if !ok {
-			continue
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
 		}
+	}
+}
+
+// resetSession forgets the session, so the next connection identifies instead of resuming
+func (c *Client) resetSession() {
+	c.heartbeatMu.Lock()
+	defer c.heartbeatMu.Unlock()
+
+	c.sessionID = ""
+	c.sequence = 0
+}
+
+// isClosed reports whether Close has been called since the client last connected
+func (c *Client) isClosed() bool {
+	return c.clientContext().Err() != nil
+}
+
+// clientContext returns a context that is canceled when the client is closed
+func (c *Client) clientContext() context.Context {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
 
-		if err = handle(op.T, c, data); err != nil {
+	return c.ctx
+}
+
+// Listen is a blocking function that will begin listening for Discord Gateway events.
+// If the connection drops or Discord asks for a reconnect, Listen reconnects and resumes
+// the session. It returns once the client is closed, or with a *GatewayCloseError if
+// Discord closes the connection for a reason reconnecting cannot fix, such as an invalid
+// token. Any other error that stops Listen, such as a READY that cannot be decoded, also
+// closes the client. Errors from handlers are passed to the ErrorHandler set by OnError
+// and do not stop Listen.
+func (c *Client) Listen() error {
+	return c.ListenContext(context.Background())
+}
+
+// ListenContext is like Listen, but closes the client and returns ctx.Err() once ctx is done.
+func (c *Client) ListenContext(ctx context.Context) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.Close()
+		case <-stop:
+		}
+	}()
+
+	for {
+		op, err := c.readOp()
+		if err == nil {
+			err = c.process(op)
+		} else if c.isClosed() {
+			return ctx.Err()
+		} else if isFatal(err) {
+			_ = c.Close()
+			return err
+		} else {
+			err = errReconnect
+		}
+
+		if err == errReconnect {
+			if err = c.reconnect(); err == nil {
+				continue
+			}
+			if c.isClosed() {
+				return ctx.Err()
+			}
+			_ = c.Close()
+			return err
+		}
+		if err != nil {
+			_ = c.Close()
 			return err
 		}
 
@@ -328,75 +785,84 @@ func (c *Client) Listen() (err error) {
 	}
 }
 
-// Handle registers an EventHandler for eventType
-func (c *Client) Handle(eventType GatewayEventType, handler EventHandler) {
-	c.handlers[eventType] = handler
+// Handle registers an EventHandler for eventType. Handlers for the same event type run
+// in the order they were registered. Handle returns a func that removes the handler.
+func (c *Client) Handle(eventType GatewayEventType, handler EventHandler) (remove func()) {
+	return c.handlers.add(eventType, handler, false)
 }
 
-// Close closes the client's connection to the Discord Gateway.
-func (c *Client) Close() error {
-	if err := c.ws.WriteMessage(websocket.CloseMessage, nil); err != nil {
-		return err
-	}
-	return c.ws.Close()
+// HandleOnce registers an EventHandler that is removed after handling the next event of eventType.
+func (c *Client) HandleOnce(eventType GatewayEventType, handler EventHandler) (remove func()) {
+	return c.handlers.add(eventType, handler, true)
 }
 
-// readChannelResponse reads JSON into a Channel
-func readChannelResponse(r io.Reader) (c Channel, err error) {
-	b, err := ioutil.ReadAll(r)
-	if err != nil {
-		return
-	}
+// Use adds middleware that wraps every handler registered with Handle or HandleOnce,
+// including those registered before. The first middleware added is the outermost.
+func (c *Client) Use(middleware ...Middleware) {
+	c.handlers.use(middleware...)
+}
 
-	var i map[string]interface{}
+// OnError sets the ErrorHandler for errors returned by the client's handlers, replacing
+// any set before. Errors are discarded if there is no ErrorHandler.
+func (c *Client) OnError(handler ErrorHandler) {
+	c.handlers.setErrorHandler(handler)
+}
 
-	if err = json.Unmarshal(b, &i); err != nil {
-		return
-	}
+// HandleAll registers a DispatchHandler, which handles every event. HandleAll returns a
+// func that removes the handler.
+func (c *Client) HandleAll(handler DispatchHandler) (remove func()) {
+	return c.handlers.addDispatch(handler)
+}
 
-	if m, ok := i["message"]; ok {
-		return c, errors.New(m.(string))
+// Shard returns the client's shard ID and the total number of shards. A client that is
+// not sharded is shard 0 of 1.
+func (c *Client) Shard() (id, count int) {
+	if c.shard == nil {
+		return 0, 1
 	}
+	return c.shard[0], c.shard[1]
+}
 
-	if id, ok := i["channel_id"].([]interface{}); ok {
-		return c, errors.New(id[0].(string))
+// HandlerIntents returns the intents needed to receive the events the client has handlers for.
+func (c *Client) HandlerIntents() Intents {
+	var intents Intents
+	for _, eventType := range c.handlers.eventTypes() {
+		intents |= IntentsFor(eventType)
 	}
+	return intents
+}
 
-	if err = json.Unmarshal(b, &c); err != nil {
-		return
+// Close closes the client's connection to the Discord Gateway and waits for its
+// background goroutines to stop. Handlers that are already running are not waited for.
+func (c *Client) Close() error {
+	c.connMu.Lock()
+	c.cancel()
+	w := c.writer
+	c.connMu.Unlock()
+
+	c.dispatcher.stop()
+
+	err := errWriterStopped
+	if w != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		err = w.send(ctx, websocket.CloseMessage, nil, true)
+		cancel()
 	}
 
-	return
+	c.disconnect()
+	c.wg.Wait()
+	return err
 }
 
 // GetChannel returns a channel by ID
 func (c *Client) GetChannel(id string) (ch Channel, err error) {
-	channel := c.channelStore.Get(id)
-	if channel == nil {
-		var req *http.Request
-		var resp *http.Response
-		var channel Channel
-
-		req, err = http.NewRequest(http.MethodGet, "https://discordapp.com/api/v6/channels/"+id, http.NoBody)
-		if err != nil {
-			return
-		}
-		req.Header.Set("Authorization", "Bot "+c.Token)
-
-		resp, err = http.DefaultClient.Do(req)
-		if err != nil {
-			return
-		}
-		defer resp.Body.Close()
-
-		channel, err = readChannelResponse(resp.Body)
-		if err != nil {
+	if channel := c.channelStore.Get(id); channel != nil {
+		ch = *channel
+	} else {
+		if err = c.request(context.Background(), http.MethodGet, "/channels/"+id, nil, &ch); err != nil {
 			return
 		}
-		c.channelStore.Add(channel)
-		ch = channel
-	} else {
-		ch = *channel
+		c.channelStore.Add(ch)
 	}
 	ch.client = c
 	return
@@ -1,18 +1,24 @@
 package discord
 
-import (
-	"github.com/mitchellh/mapstructure"
-)
+import "encoding/json"
 
+// EventHandler handles the data of a gateway event, which is the event's JSON payload.
 type EventHandler interface {
-	Handle(*Client, map[string]interface{}) error
+	Handle(*Client, json.RawMessage) error
+}
+
+// EventHandlerFunc is an EventHandler that works with the event's JSON payload directly.
+type EventHandlerFunc func(client *Client, data json.RawMessage) error
+
+func (h EventHandlerFunc) Handle(client *Client, data json.RawMessage) error {
+	return h(client, data)
 }
 
 type MessageHandler func(client *Client, message Message)
 
-func (h MessageHandler) Handle(client *Client, i map[string]interface{}) error {
+func (h MessageHandler) Handle(client *Client, data json.RawMessage) error {
 	var m Message
-	if err := mapstructure.Decode(i, &m); err != nil {
+	if err := json.Unmarshal(data, &m); err != nil {
 		return err
 	}
 	h(client, m)
@@ -21,9 +27,9 @@ func (h MessageHandler) Handle(client *Client, i map[string]interface{}) error {
 
 type PresenceHandler func(client *Client, presence Presence)
 
-func (ph PresenceHandler) Handle(client *Client, data map[string]interface{}) error {
+func (ph PresenceHandler) Handle(client *Client, data json.RawMessage) error {
 	var p Presence
-	if err := mapstructure.Decode(data, &p); err != nil {
+	if err := json.Unmarshal(data, &p); err != nil {
 		return err
 	}
 	ph(client, p)
@@ -32,9 +38,9 @@ func (ph PresenceHandler) Handle(client *Client, data map[string]interface{}) er
 
 type UserUpdateHandler func(client *Client, user User)
 
-func (handler UserUpdateHandler) Handle(client *Client, data map[string]interface{}) error {
+func (handler UserUpdateHandler) Handle(client *Client, data json.RawMessage) error {
 	var user User
-	if err := mapstructure.Decode(data, &user); err != nil {
+	if err := json.Unmarshal(data, &user); err != nil {
 		return err
 	}
 	handler(client, user)
@@ -43,9 +49,270 @@ func (handler UserUpdateHandler) Handle(client *Client, data map[string]interfac
 
 type GuildMemberUpdateHandler func(client *Client, update GuildMemberUpdate)
 
-func (h GuildMemberUpdateHandler) Handle(client *Client, data map[string]interface{}) error {
+func (h GuildMemberUpdateHandler) Handle(client *Client, data json.RawMessage) error {
 	var update GuildMemberUpdate
-	if err := mapstructure.Decode(data, &update); err != nil {
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	h(client, update)
+	return nil
+}
+
+// ReadyHandler handles READY events.
+type ReadyHandler func(client *Client, ready Ready)
+
+func (h ReadyHandler) Handle(client *Client, data json.RawMessage) error {
+	var ready Ready
+	if err := json.Unmarshal(data, &ready); err != nil {
+		return err
+	}
+	h(client, ready)
+	return nil
+}
+
+// ResumedHandler handles RESUMED events.
+type ResumedHandler func(client *Client)
+
+func (h ResumedHandler) Handle(client *Client, data json.RawMessage) error {
+	h(client)
+	return nil
+}
+
+// ChannelHandler handles CHANNEL_CREATE, CHANNEL_UPDATE and CHANNEL_DELETE events.
+type ChannelHandler func(client *Client, channel Channel)
+
+func (h ChannelHandler) Handle(client *Client, data json.RawMessage) error {
+	var channel Channel
+	if err := json.Unmarshal(data, &channel); err != nil {
+		return err
+	}
+	channel.client = client
+	h(client, channel)
+	return nil
+}
+
+// ChannelPinsUpdateHandler handles CHANNEL_PINS_UPDATE events.
+type ChannelPinsUpdateHandler func(client *Client, update ChannelPinsUpdate)
+
+func (h ChannelPinsUpdateHandler) Handle(client *Client, data json.RawMessage) error {
+	var update ChannelPinsUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	h(client, update)
+	return nil
+}
+
+// GuildHandler handles GUILD_CREATE and GUILD_UPDATE events.
+type GuildHandler func(client *Client, guild Guild)
+
+func (h GuildHandler) Handle(client *Client, data json.RawMessage) error {
+	var guild Guild
+	if err := json.Unmarshal(data, &guild); err != nil {
+		return err
+	}
+	h(client, guild)
+	return nil
+}
+
+// GuildDeleteHandler handles GUILD_DELETE events.
+type GuildDeleteHandler func(client *Client, guild UnavailableGuild)
+
+func (h GuildDeleteHandler) Handle(client *Client, data json.RawMessage) error {
+	var guild UnavailableGuild
+	if err := json.Unmarshal(data, &guild); err != nil {
+		return err
+	}
+	h(client, guild)
+	return nil
+}
+
+// GuildBanHandler handles GUILD_BAN_ADD and GUILD_BAN_REMOVE events.
+type GuildBanHandler func(client *Client, ban GuildBan)
+
+func (h GuildBanHandler) Handle(client *Client, data json.RawMessage) error {
+	var ban GuildBan
+	if err := json.Unmarshal(data, &ban); err != nil {
+		return err
+	}
+	h(client, ban)
+	return nil
+}
+
+// GuildEmojisUpdateHandler handles GUILD_EMOJIS_UPDATE events.
+type GuildEmojisUpdateHandler func(client *Client, update GuildEmojisUpdate)
+
+func (h GuildEmojisUpdateHandler) Handle(client *Client, data json.RawMessage) error {
+	var update GuildEmojisUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	h(client, update)
+	return nil
+}
+
+// GuildIntegrationsUpdateHandler handles GUILD_INTEGRATIONS_UPDATE events.
+type GuildIntegrationsUpdateHandler func(client *Client, update GuildIntegrationsUpdate)
+
+func (h GuildIntegrationsUpdateHandler) Handle(client *Client, data json.RawMessage) error {
+	var update GuildIntegrationsUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	h(client, update)
+	return nil
+}
+
+// GuildMemberAddHandler handles GUILD_MEMBER_ADD events.
+type GuildMemberAddHandler func(client *Client, member GuildMemberAdd)
+
+func (h GuildMemberAddHandler) Handle(client *Client, data json.RawMessage) error {
+	var member GuildMemberAdd
+	if err := json.Unmarshal(data, &member); err != nil {
+		return err
+	}
+	h(client, member)
+	return nil
+}
+
+// GuildMemberRemoveHandler handles GUILD_MEMBER_REMOVE events.
+type GuildMemberRemoveHandler func(client *Client, member GuildMemberRemove)
+
+func (h GuildMemberRemoveHandler) Handle(client *Client, data json.RawMessage) error {
+	var member GuildMemberRemove
+	if err := json.Unmarshal(data, &member); err != nil {
+		return err
+	}
+	h(client, member)
+	return nil
+}
+
+// GuildMembersChunkHandler handles GUILD_MEMBERS_CHUNK events.
+type GuildMembersChunkHandler func(client *Client, chunk GuildMembersChunk)
+
+func (h GuildMembersChunkHandler) Handle(client *Client, data json.RawMessage) error {
+	var chunk GuildMembersChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return err
+	}
+	h(client, chunk)
+	return nil
+}
+
+// GuildRoleHandler handles GUILD_ROLE_CREATE and GUILD_ROLE_UPDATE events.
+type GuildRoleHandler func(client *Client, role GuildRole)
+
+func (h GuildRoleHandler) Handle(client *Client, data json.RawMessage) error {
+	var role GuildRole
+	if err := json.Unmarshal(data, &role); err != nil {
+		return err
+	}
+	h(client, role)
+	return nil
+}
+
+// GuildRoleDeleteHandler handles GUILD_ROLE_DELETE events.
+type GuildRoleDeleteHandler func(client *Client, role GuildRoleDelete)
+
+func (h GuildRoleDeleteHandler) Handle(client *Client, data json.RawMessage) error {
+	var role GuildRoleDelete
+	if err := json.Unmarshal(data, &role); err != nil {
+		return err
+	}
+	h(client, role)
+	return nil
+}
+
+// MessageDeleteHandler handles MESSAGE_DELETE events.
+type MessageDeleteHandler func(client *Client, message MessageDelete)
+
+func (h MessageDeleteHandler) Handle(client *Client, data json.RawMessage) error {
+	var message MessageDelete
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+	h(client, message)
+	return nil
+}
+
+// MessageDeleteBulkHandler handles MESSAGE_DELETE_BULK events.
+type MessageDeleteBulkHandler func(client *Client, messages MessageDeleteBulk)
+
+func (h MessageDeleteBulkHandler) Handle(client *Client, data json.RawMessage) error {
+	var messages MessageDeleteBulk
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+	h(client, messages)
+	return nil
+}
+
+// MessageReactionHandler handles MESSAGE_REACTION_ADD and MESSAGE_REACTION_REMOVE events.
+type MessageReactionHandler func(client *Client, reaction MessageReaction)
+
+func (h MessageReactionHandler) Handle(client *Client, data json.RawMessage) error {
+	var reaction MessageReaction
+	if err := json.Unmarshal(data, &reaction); err != nil {
+		return err
+	}
+	h(client, reaction)
+	return nil
+}
+
+// MessageReactionRemoveAllHandler handles MESSAGE_REACTION_REMOVE_ALL events.
+type MessageReactionRemoveAllHandler func(client *Client, reactions MessageReactionRemoveAll)
+
+func (h MessageReactionRemoveAllHandler) Handle(client *Client, data json.RawMessage) error {
+	var reactions MessageReactionRemoveAll
+	if err := json.Unmarshal(data, &reactions); err != nil {
+		return err
+	}
+	h(client, reactions)
+	return nil
+}
+
+// TypingStartHandler handles TYPING_START events.
+type TypingStartHandler func(client *Client, typing TypingStart)
+
+func (h TypingStartHandler) Handle(client *Client, data json.RawMessage) error {
+	var typing TypingStart
+	if err := json.Unmarshal(data, &typing); err != nil {
+		return err
+	}
+	h(client, typing)
+	return nil
+}
+
+// VoiceStateUpdateHandler handles VOICE_STATE_UPDATE events.
+type VoiceStateUpdateHandler func(client *Client, state VoiceState)
+
+func (h VoiceStateUpdateHandler) Handle(client *Client, data json.RawMessage) error {
+	var state VoiceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	h(client, state)
+	return nil
+}
+
+// VoiceServerUpdateHandler handles VOICE_SERVER_UPDATE events.
+type VoiceServerUpdateHandler func(client *Client, update VoiceServerUpdate)
+
+func (h VoiceServerUpdateHandler) Handle(client *Client, data json.RawMessage) error {
+	var update VoiceServerUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		return err
+	}
+	h(client, update)
+	return nil
+}
+
+// WebhooksUpdateHandler handles WEBHOOKS_UPDATE events.
+type WebhooksUpdateHandler func(client *Client, update WebhooksUpdate)
+
+func (h WebhooksUpdateHandler) Handle(client *Client, data json.RawMessage) error {
+	var update WebhooksUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
 		return err
 	}
 	h(client, update)
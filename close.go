@@ -0,0 +1,84 @@
+package discord
+
+import (
+	"fmt"
+	"github.com/gorilla/websocket"
+)
+
+// Gateway close codes
+const (
+	CloseUnknownError         = 4000
+	CloseUnknownOpcode        = 4001
+	CloseDecodeError          = 4002
+	CloseNotAuthenticated     = 4003
+	CloseAuthenticationFailed = 4004
+	CloseAlreadyAuthenticated = 4005
+	CloseInvalidSeq           = 4007
+	CloseRateLimited          = 4008
+	CloseSessionTimedOut      = 4009
+	CloseInvalidShard         = 4010
+	CloseShardingRequired     = 4011
+	CloseInvalidAPIVersion    = 4012
+	CloseInvalidIntents       = 4013
+	CloseDisallowedIntents    = 4014
+)
+
+// Descriptions of close codes, for close frames without a reason
+var closeReasons = map[int]string{
+	CloseUnknownError:         "Unknown error",
+	CloseUnknownOpcode:        "Unknown opcode",
+	CloseDecodeError:          "Decode error",
+	CloseNotAuthenticated:     "Not authenticated",
+	CloseAuthenticationFailed: "Authentication failed",
+	CloseAlreadyAuthenticated: "Already authenticated",
+	CloseInvalidSeq:           "Invalid seq",
+	CloseRateLimited:          "Rate limited",
+	CloseSessionTimedOut:      "Session timed out",
+	CloseInvalidShard:         "Invalid shard",
+	CloseShardingRequired:     "Sharding required",
+	CloseInvalidAPIVersion:    "Invalid API version",
+	CloseInvalidIntents:       "Invalid intent(s)",
+	CloseDisallowedIntents:    "Disallowed intent(s)",
+}
+
+// GatewayCloseError is returned when Discord closes the gateway connection.
+type GatewayCloseError struct {
+	Code   int
+	Reason string
+}
+
+// newGatewayCloseError converts the error for a close frame read from the gateway
+func newGatewayCloseError(err *websocket.CloseError) *GatewayCloseError {
+	reason := err.Text
+	if reason == "" {
+		reason = closeReasons[err.Code]
+	}
+	return &GatewayCloseError{Code: err.Code, Reason: reason}
+}
+
+func (e *GatewayCloseError) Error() string {
+	return fmt.Sprintf("discord: gateway closed with %d: %s", e.Code, e.Reason)
+}
+
+// Reconnectable reports whether connecting again can succeed. It is false for codes that
+// mean the client must be configured differently first, such as CloseAuthenticationFailed.
+func (e *GatewayCloseError) Reconnectable() bool {
+	switch e.Code {
+	case CloseAuthenticationFailed, CloseInvalidShard, CloseShardingRequired,
+		CloseInvalidAPIVersion, CloseInvalidIntents, CloseDisallowedIntents:
+		return false
+	}
+	return true
+}
+
+// sessionInvalid reports whether the session cannot be resumed after the close
+func (e *GatewayCloseError) sessionInvalid() bool {
+	return e.Code == CloseInvalidSeq || e.Code == CloseSessionTimedOut
+}
+
+// isFatal reports whether err is Discord closing the connection for a reason reconnecting
+// cannot fix
+func isFatal(err error) bool {
+	closeErr, ok := err.(*GatewayCloseError)
+	return ok && !closeErr.Reconnectable()
+}
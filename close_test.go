@@ -0,0 +1,29 @@
+package discord
+
+import (
+	"github.com/gorilla/websocket"
+	"testing"
+)
+
+func TestGatewayCloseError(t *testing.T) {
+	tests := []struct {
+		err           *websocket.CloseError
+		reason        string
+		reconnectable bool
+	}{
+		{&websocket.CloseError{Code: CloseAuthenticationFailed, Text: "Authentication failed."}, "Authentication failed.", false},
+		{&websocket.CloseError{Code: CloseDisallowedIntents}, "Disallowed intent(s)", false},
+		{&websocket.CloseError{Code: CloseSessionTimedOut}, "Session timed out", true},
+		{&websocket.CloseError{Code: websocket.CloseGoingAway}, "", true},
+	}
+
+	for _, test := range tests {
+		err := newGatewayCloseError(test.err)
+		if err.Code != test.err.Code || err.Reason != test.reason || err.Reconnectable() != test.reconnectable {
+			t.Errorf("%d: got %+v, reconnectable %v", test.err.Code, err, err.Reconnectable())
+		}
+		if isFatal(err) == test.reconnectable {
+			t.Errorf("%d: isFatal is %v", test.err.Code, isFatal(err))
+		}
+	}
+}
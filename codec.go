@@ -0,0 +1,57 @@
+package discord
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+)
+
+// Encoding is a format for gateway payloads.
+type Encoding string
+
+// Gateway encodings
+const (
+	EncodingJSON Encoding = "json"
+	EncodingETF  Encoding = "etf"
+)
+
+// WithEncoding makes the client use encoding for gateway payloads. The default is EncodingJSON.
+func WithEncoding(encoding Encoding) ClientOption {
+	return func(c *Client) {
+		switch encoding {
+		case EncodingETF:
+			c.codec = etfCodec{}
+		default:
+			c.codec = jsonCodec{}
+		}
+	}
+}
+
+// codec encodes and decodes gateway payloads. The d field of a decoded payload is always
+// JSON, so the rest of the client does not depend on the encoding.
+type codec interface {
+	// encoding is the value of the gateway's encoding query parameter
+	encoding() Encoding
+	// messageType is the websocket message type payloads are sent in
+	messageType() int
+	encode(cmd gatewayCommand) ([]byte, error)
+	decode(b []byte, op *gatewayOp) error
+}
+
+// jsonCodec encodes payloads as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) encoding() Encoding {
+	return EncodingJSON
+}
+
+func (jsonCodec) messageType() int {
+	return websocket.TextMessage
+}
+
+func (jsonCodec) encode(cmd gatewayCommand) ([]byte, error) {
+	return json.Marshal(cmd)
+}
+
+func (jsonCodec) decode(b []byte, op *gatewayOp) error {
+	return json.Unmarshal(b, op)
+}
@@ -0,0 +1,438 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// DispatchMode is how a client runs the handlers for the events it receives.
+type DispatchMode int
+
+const (
+	// DispatchSync runs handlers on a single goroutine, one event at a time, in the order
+	// events are received. A slow handler delays the handling of every later event, but
+	// not its reading, so a handler can wait for later events with WaitFor. Events wait in
+	// memory for as long as handlers take. This is the default.
+	DispatchSync DispatchMode = iota
+	// DispatchConcurrent runs the handlers for each event on a new goroutine, so events
+	// are not handled in any particular order.
+	DispatchConcurrent
+	// DispatchWorkerPool runs handlers on a fixed number of goroutines. Events for the
+	// same channel, or for the same guild if they have no channel, are handled in the
+	// order they were received.
+	DispatchWorkerPool
+)
+
+// Events queued for a worker before reading from the gateway waits for it
+const workerQueueSize = 64
+
+// WithDispatchMode makes the client run handlers according to mode. A worker pool has
+// one worker per CPU unless WithWorkerPool sets its size.
+func WithDispatchMode(mode DispatchMode) ClientOption {
+	return func(c *Client) {
+		c.dispatcher.mode = mode
+	}
+}
+
+// WithWorkerPool makes the client run handlers on a pool of workers goroutines, as
+// described by DispatchWorkerPool.
+func WithWorkerPool(workers int) ClientOption {
+	return func(c *Client) {
+		c.dispatcher.mode = DispatchWorkerPool
+		c.dispatcher.workers = workers
+	}
+}
+
+// ErrorHandler handles an error returned by a handler for an event, or a handler panic.
+// It is called once for each handler that fails, and the event's other handlers still run.
+// data is the event's payload.
+type ErrorHandler func(client *Client, eventType GatewayEventType, data json.RawMessage, err error)
+
+// PanicError is the error reported when a handler panics.
+type PanicError struct {
+	EventType GatewayEventType
+	Value     interface{} // The value passed to panic
+	Stack     []byte      // The handler's stack trace
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("discord: %s handler panicked: %v", e.EventType, e.Value)
+}
+
+// DispatchHandler handles every event a client receives, before the handlers for the event's type.
+type DispatchHandler func(client *Client, eventType GatewayEventType, data json.RawMessage)
+
+// registeredHandler is an EventHandler added to a handlerSet
+type registeredHandler struct {
+	handler EventHandler
+	once    bool
+}
+
+// registeredDispatchHandler is a DispatchHandler added to a handlerSet
+type registeredDispatchHandler struct {
+	handler DispatchHandler
+}
+
+// handlerSet holds the handlers of a client, or of every shard of a ShardManager
+type handlerSet struct {
+	mu         sync.Mutex
+	byType     map[GatewayEventType][]*registeredHandler
+	dispatch   []*registeredDispatchHandler
+	middleware []Middleware
+	onError    ErrorHandler
+}
+
+func newHandlerSet() *handlerSet {
+	return &handlerSet{byType: map[GatewayEventType][]*registeredHandler{}}
+}
+
+// add registers handler for eventType and returns a func that removes it
+func (s *handlerSet) add(eventType GatewayEventType, handler EventHandler, once bool) func() {
+	h := &registeredHandler{handler: handler, once: once}
+
+	s.mu.Lock()
+	s.byType[eventType] = append(s.byType[eventType], h)
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		handlers := s.byType[eventType]
+		for i, registered := range handlers {
+			if registered == h {
+				s.byType[eventType] = append(handlers[:i:i], handlers[i+1:]...)
+				break
+			}
+		}
+		if len(s.byType[eventType]) == 0 {
+			delete(s.byType, eventType)
+		}
+	}
+}
+
+// addDispatch registers a DispatchHandler and returns a func that removes it
+func (s *handlerSet) addDispatch(handler DispatchHandler) func() {
+	h := &registeredDispatchHandler{handler: handler}
+
+	s.mu.Lock()
+	s.dispatch = append(s.dispatch, h)
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i, registered := range s.dispatch {
+			if registered == h {
+				s.dispatch = append(s.dispatch[:i:i], s.dispatch[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// use adds middleware, which wraps every EventHandler from then on
+func (s *handlerSet) use(middleware ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.middleware = append(s.middleware, middleware...)
+}
+
+// get returns the handlers to run for an event of eventType, wrapped in the middleware.
+// Handlers registered to run once are removed, so no later event runs them.
+func (s *handlerSet) get(eventType GatewayEventType) ([]DispatchHandler, []EventHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dispatch := make([]DispatchHandler, len(s.dispatch))
+	for i, h := range s.dispatch {
+		dispatch[i] = h.handler
+	}
+
+	registered := s.byType[eventType]
+	handlers := make([]EventHandler, len(registered))
+	kept := registered[:0:0]
+	for i, h := range registered {
+		handlers[i] = h.handler
+		for j := len(s.middleware) - 1; j >= 0; j-- {
+			handlers[i] = s.middleware[j](handlers[i])
+		}
+		if !h.once {
+			kept = append(kept, h)
+		}
+	}
+	if len(kept) != len(registered) {
+		s.byType[eventType] = kept
+	}
+
+	return dispatch, handlers
+}
+
+// setErrorHandler replaces the ErrorHandler
+func (s *handlerSet) setErrorHandler(handler ErrorHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.onError = handler
+}
+
+// errorHandler returns the ErrorHandler, which may be nil
+func (s *handlerSet) errorHandler() ErrorHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.onError
+}
+
+// eventTypes returns every event type with a handler
+func (s *handlerSet) eventTypes() []GatewayEventType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eventTypes := make([]GatewayEventType, 0, len(s.byType))
+	for eventType := range s.byType {
+		eventTypes = append(eventTypes, eventType)
+	}
+	return eventTypes
+}
+
+// handle runs the handlers for an event. A handler that fails or panics does not stop the
+// others; its error, or a *PanicError for a panic, is passed to the client's ErrorHandler.
+func handle(eventType GatewayEventType, c *Client, data json.RawMessage) {
+	dispatch, handlers := c.handlers.get(eventType)
+
+	for _, h := range dispatch {
+		h := h
+		report(c, eventType, data, recovered(eventType, func() error {
+			h(c, eventType, data)
+			return nil
+		}))
+	}
+	for _, h := range handlers {
+		h := h
+		report(c, eventType, data, recovered(eventType, func() error {
+			return h.Handle(c, data)
+		}))
+	}
+}
+
+// recovered calls f, returning a panic in f as a *PanicError
+func recovered(eventType GatewayEventType, f func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{EventType: eventType, Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return f()
+}
+
+// report passes a handler's error, if any, to the client's ErrorHandler
+func report(c *Client, eventType GatewayEventType, data json.RawMessage, err error) {
+	if err == nil {
+		return
+	}
+	if onError := c.handlers.errorHandler(); onError != nil {
+		onError(c, eventType, data, err)
+	}
+}
+
+// dispatchedEvent is an event waiting for a worker or the handler goroutine
+type dispatchedEvent struct {
+	eventType GatewayEventType
+	data      json.RawMessage
+}
+
+// dispatcher runs handlers for a client according to its DispatchMode
+type dispatcher struct {
+	mode    DispatchMode
+	workers int
+
+	mu      sync.Mutex
+	queues  []chan dispatchedEvent
+	pending []dispatchedEvent // Events waiting for the handler goroutine of DispatchSync
+	wake    chan struct{}
+	stopped bool
+	done    chan struct{}
+}
+
+// dispatch runs the handlers for an event, or arranges for them to run later. Errors from
+// handlers are passed to the client's ErrorHandler.
+func (d *dispatcher) dispatch(c *Client, eventType GatewayEventType, data json.RawMessage) {
+	switch d.mode {
+	case DispatchConcurrent:
+		if d.isStopped() {
+			return
+		}
+		go handle(eventType, c, data)
+	case DispatchWorkerPool:
+		queue, done := d.queue(c, eventKey(eventType, data))
+		if queue == nil {
+			return
+		}
+		select {
+		case queue <- dispatchedEvent{eventType: eventType, data: data}:
+		case <-done:
+		}
+	default:
+		d.enqueue(c, dispatchedEvent{eventType: eventType, data: data})
+	}
+}
+
+// enqueue adds an event to those waiting for the handler goroutine, starting it if needed.
+// Nothing bounds the events waiting, so reading from the gateway never waits for a handler.
+func (d *dispatcher) enqueue(c *Client, e dispatchedEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	if d.wake == nil {
+		d.done = make(chan struct{})
+		d.wake = make(chan struct{}, 1)
+		go d.handleInOrder(c, d.wake, d.done)
+	}
+
+	d.pending = append(d.pending, e)
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// handleInOrder runs the handlers for pending events, one event at a time, until done is
+// closed
+func (d *dispatcher) handleInOrder(c *Client, wake, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-wake:
+		}
+
+		for {
+			e, ok := d.next(done)
+			if !ok {
+				break
+			}
+			handle(e.eventType, c, e.data)
+		}
+	}
+}
+
+// next removes and returns the oldest pending event. It returns false if there is none,
+// or if done is closed, since the events pending then belong to a later handler goroutine.
+func (d *dispatcher) next(done chan struct{}) (dispatchedEvent, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	select {
+	case <-done:
+		return dispatchedEvent{}, false
+	default:
+	}
+	if len(d.pending) == 0 {
+		return dispatchedEvent{}, false
+	}
+
+	e := d.pending[0]
+	d.pending[0] = dispatchedEvent{}
+	d.pending = d.pending[1:]
+	return e, true
+}
+
+// queue returns the queue of the worker for events with key and the channel closed when
+// the workers stop, starting the workers if needed. It returns nil once the dispatcher is
+// stopped.
+func (d *dispatcher) queue(c *Client, key string) (chan dispatchedEvent, chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return nil, nil
+	}
+	if d.queues == nil {
+		n := d.workers
+		if n < 1 {
+			n = runtime.NumCPU()
+		}
+		d.done = make(chan struct{})
+		d.queues = make([]chan dispatchedEvent, n)
+		for i := range d.queues {
+			d.queues[i] = make(chan dispatchedEvent, workerQueueSize)
+			go work(c, d.queues[i], d.done)
+		}
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return d.queues[h.Sum32()%uint32(len(d.queues))], d.done
+}
+
+// work runs the handlers for events from queue until done is closed
+func work(c *Client, queue chan dispatchedEvent, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case e := <-queue:
+			handle(e.eventType, c, e.data)
+		}
+	}
+}
+
+func (d *dispatcher) isStopped() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.stopped
+}
+
+// start lets a stopped dispatcher run handlers again
+func (d *dispatcher) start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = false
+	d.queues = nil
+	d.pending = nil
+	d.wake = nil
+}
+
+// stop stops the workers or the handler goroutine. Queued events are dropped, and handlers
+// already running are not waited for, so a handler may close its client.
+func (d *dispatcher) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.stopped && d.done != nil {
+		close(d.done)
+	}
+	d.stopped = true
+	d.pending = nil
+}
+
+// eventKey returns the ID of the channel an event is for, or of its guild if it is not for
+// a channel. Events with the same key are handled in order by a worker pool.
+func eventKey(eventType GatewayEventType, data json.RawMessage) string {
+	var ids struct {
+		ID        string `json:"id"`
+		ChannelID string `json:"channel_id"`
+		GuildID   string `json:"guild_id"`
+	}
+	_ = json.Unmarshal(data, &ids)
+
+	switch {
+	case eventType == GatewayChannelCreate || eventType == GatewayChannelUpdate || eventType == GatewayChannelDelete:
+		return ids.ID
+	case ids.ChannelID != "":
+		return ids.ChannelID
+	}
+	return ids.GuildID
+}
@@ -0,0 +1,269 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHandlers(t *testing.T) {
+	c := NewClient("")
+	failOnError(t, c)
+
+	var calls []string
+	record := func(name string) EventHandler {
+		return MessageHandler(func(*Client, Message) {
+			calls = append(calls, name)
+		})
+	}
+
+	c.Handle(GatewayMessageCreate, record("first"))
+	remove := c.Handle(GatewayMessageCreate, record("removed"))
+	c.HandleOnce(GatewayMessageCreate, record("once"))
+	c.Handle(GatewayMessageCreate, record("last"))
+	c.HandleAll(func(_ *Client, eventType GatewayEventType, _ json.RawMessage) {
+		calls = append(calls, "all "+string(eventType))
+	})
+	remove()
+
+	for i := 0; i < 2; i++ {
+		handle(GatewayMessageCreate, c, json.RawMessage(`{}`))
+	}
+
+	want := []string{
+		"all MESSAGE_CREATE", "first", "once", "last",
+		"all MESSAGE_CREATE", "first", "last",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("got %q, want %q", calls, want)
+	}
+}
+
+func TestWorkerPool(t *testing.T) {
+	c := NewClient("", WithWorkerPool(4))
+	defer c.Close()
+
+	const events = 300
+	var (
+		mu   sync.Mutex
+		seen = map[string][]int{}
+		wg   sync.WaitGroup
+	)
+	wg.Add(events)
+	c.Handle(GatewayMessageCreate, MessageHandler(func(_ *Client, m Message) {
+		defer wg.Done()
+
+		var n int
+		fmt.Sscan(m.Content, &n)
+		mu.Lock()
+		seen[m.ChannelID] = append(seen[m.ChannelID], n)
+		mu.Unlock()
+	}))
+
+	for i := 0; i < events; i++ {
+		data := fmt.Sprintf(`{"channel_id":"%d","content":"%d"}`, i%7, i)
+		c.dispatcher.dispatch(c, GatewayMessageCreate, json.RawMessage(data))
+	}
+	wg.Wait()
+
+	for channel, ns := range seen {
+		for i := 1; i < len(ns); i++ {
+			if ns[i] < ns[i-1] {
+				t.Fatalf("channel %s: events handled out of order: %v", channel, ns)
+			}
+		}
+	}
+}
+
+// failOnError makes handler errors on c fail the test
+func failOnError(t *testing.T, c *Client) {
+	c.OnError(func(_ *Client, eventType GatewayEventType, _ json.RawMessage, err error) {
+		t.Errorf("%s: %v", eventType, err)
+	})
+}
+
+func TestHandlerPanic(t *testing.T) {
+	c := NewClient("")
+	var calls []string
+	c.HandleAll(func(*Client, GatewayEventType, json.RawMessage) {
+		panic("all")
+	})
+	c.Handle(GatewayMessageCreate, MessageHandler(func(*Client, Message) {
+		panic("oops")
+	}))
+	c.Handle(GatewayMessageCreate, MessageHandler(func(*Client, Message) {
+		calls = append(calls, "after")
+	}))
+
+	var errs []error
+	c.OnError(func(_ *Client, _ GatewayEventType, _ json.RawMessage, err error) {
+		errs = append(errs, err)
+	})
+
+	handle(GatewayMessageCreate, c, json.RawMessage(`{}`))
+
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want one for each panic", errs)
+	}
+	for i, value := range []string{"all", "oops"} {
+		if p, ok := errs[i].(*PanicError); !ok || p.Value != value || p.EventType != GatewayMessageCreate {
+			t.Errorf("got %v, want a *PanicError for %q", errs[i], value)
+		}
+	}
+	if !reflect.DeepEqual(calls, []string{"after"}) {
+		t.Errorf("handlers after the panics were not run")
+	}
+}
+
+func TestOnError(t *testing.T) {
+	c := NewClient("")
+	c.Handle(GatewayMessageCreate, MessageHandler(func(*Client, Message) {}))
+	c.Handle(GatewayMessageCreate, EventHandlerFunc(func(*Client, json.RawMessage) error {
+		return errors.New("second")
+	}))
+	ran := false
+	c.Handle(GatewayMessageCreate, EventHandlerFunc(func(*Client, json.RawMessage) error {
+		ran = true
+		return nil
+	}))
+
+	var (
+		eventTypes []GatewayEventType
+		data       []json.RawMessage
+		errs       []error
+	)
+	c.OnError(func(_ *Client, t GatewayEventType, d json.RawMessage, e error) {
+		eventTypes, data, errs = append(eventTypes, t), append(data, d), append(errs, e)
+	})
+
+	// content is not a string, so the first handler fails to decode the message
+	payload := json.RawMessage(`{"content":5}`)
+	handle(GatewayMessageCreate, c, payload)
+
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want one from each failing handler", errs)
+	}
+	if _, ok := errs[0].(*json.UnmarshalTypeError); !ok {
+		t.Errorf("got error %v, want a *json.UnmarshalTypeError", errs[0])
+	}
+	if errs[1].Error() != "second" {
+		t.Errorf("got error %v from the second handler", errs[1])
+	}
+	for i := range errs {
+		if eventTypes[i] != GatewayMessageCreate || string(data[i]) != string(payload) {
+			t.Errorf("got %v %s", eventTypes[i], data[i])
+		}
+	}
+	if !ran {
+		t.Error("handler after the errors was not run")
+	}
+}
+
+// messageCreate is a MESSAGE_CREATE payload as Discord sends it
+var messageCreate = []byte(`{"op":0,"s":42,"t":"MESSAGE_CREATE","d":{` +
+	`"id":"626310416841474048","channel_id":"626305707233411074","guild_id":"626305707233411072",` +
+	`"author":{"id":"183683290473840640","username":"riley","discriminator":"0001","avatar":"a1b2c3","bot":false},` +
+	`"member":{"roles":["626306160839802881"],"nick":"Riley","joined_at":"2019-09-23T01:10:23.342000+00:00","premium_since":null,"deaf":false,"mute":false},` +
+	`"content":"hello <@&626306160839802881>","timestamp":"2019-09-23T01:36:42.171000+00:00","edited_timestamp":null,` +
+	`"tts":false,"mention_everyone":false,"mentions":[],"mention_roles":["626306160839802881"],` +
+	`"attachments":[{"id":"626310416430432256","filename":"cat.png","size":4096,"url":"https://cdn.discordapp.com/cat.png","proxy_url":"https://media.discordapp.net/cat.png","height":128,"width":128}],` +
+	`"embeds":[{"title":"Cat","type":"rich","timestamp":"2019-09-23T01:36:00+00:00","color":16711680}],` +
+	`"nonce":"626310415255601152","pinned":false,"type":0,"flags":0}}`)
+
+func TestMessageCreate(t *testing.T) {
+	var op gatewayOp
+	if err := json.Unmarshal(messageCreate, &op); err != nil {
+		t.Fatal(err)
+	}
+
+	var message Message
+	c := NewClient("")
+	failOnError(t, c)
+	c.Handle(GatewayMessageCreate, MessageHandler(func(_ *Client, m Message) {
+		message = m
+	}))
+	handle(op.T, c, op.D)
+
+	if want := time.Date(2019, 9, 23, 1, 36, 42, 171000000, time.UTC); !message.Timestamp.Equal(want) {
+		t.Errorf("got timestamp %v, want %v", message.Timestamp, want)
+	}
+	if message.EditedTimestamp != nil {
+		t.Errorf("got edited timestamp %v, want nil", message.EditedTimestamp)
+	}
+	if message.Member == nil || message.Member.Nickname != "Riley" || message.Member.JoinedAt.IsZero() {
+		t.Errorf("got member %+v", message.Member)
+	}
+	if !reflect.DeepEqual(message.MentionRoles, []string{"626306160839802881"}) {
+		t.Errorf("got mention roles %q", message.MentionRoles)
+	}
+	if len(message.Attachments) != 1 || message.Attachments[0].ProxyURL == "" {
+		t.Errorf("got attachments %+v", message.Attachments)
+	}
+	if len(message.Embeds) != 1 || message.Embeds[0].Timestamp == nil {
+		t.Errorf("got embeds %+v", message.Embeds)
+	}
+	if message.Nonce != "626310415255601152" {
+		t.Errorf("got nonce %q", message.Nonce)
+	}
+
+	// Nonces sent as integers are sent back as integers
+	d := strings.Replace(string(op.D), `"nonce":"626310415255601152"`, `"nonce":626310415255601152`, 1)
+	handle(op.T, c, json.RawMessage(d))
+	if message.Nonce != "626310415255601152" {
+		t.Errorf("got integer nonce %q", message.Nonce)
+	}
+}
+
+// BenchmarkMessageCreate compares decoding MESSAGE_CREATE into a map and then into a
+// Message with mapstructure, as the client used to, with decoding d directly.
+func BenchmarkMessageCreate(b *testing.B) {
+	b.Run("mapstructure", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var op struct {
+				Op int                    `json:"op"`
+				D  map[string]interface{} `json:"d"`
+				S  int                    `json:"s"`
+				T  GatewayEventType       `json:"t"`
+			}
+			if err := json.Unmarshal(messageCreate, &op); err != nil {
+				b.Fatal(err)
+			}
+
+			var message Message
+			d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook:       mapstructure.StringToTimeHookFunc(time.RFC3339Nano),
+				WeaklyTypedInput: true,
+				TagName:          "json",
+				Result:           &message,
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err = d.Decode(op.D); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("json.RawMessage", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var op gatewayOp
+			if err := json.Unmarshal(messageCreate, &op); err != nil {
+				b.Fatal(err)
+			}
+
+			var message Message
+			if err := json.Unmarshal(op.D, &message); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
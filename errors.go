@@ -0,0 +1,111 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Discord JSON error codes
+const (
+	ErrCodeUnknownChannel         = 10003
+	ErrCodeUnknownGuild           = 10004
+	ErrCodeUnknownMember          = 10007
+	ErrCodeUnknownMessage         = 10008
+	ErrCodeUnknownRole            = 10011
+	ErrCodeUnknownUser            = 10013
+	ErrCodeUnknownEmoji           = 10014
+	ErrCodeUnknownWebhook         = 10015
+	ErrCodeMissingAccess          = 50001
+	ErrCodeCannotSendEmptyMessage = 50006
+	ErrCodeCannotMessageUser      = 50007
+	ErrCodeMissingPermissions     = 50013
+	ErrCodeInvalidFormBody        = 50035
+)
+
+// APIError is the error returned when Discord rejects a REST request.
+type APIError struct {
+	StatusCode int    `json:"-"`       // The HTTP status code
+	Code       int    `json:"code"`    // Discord's JSON error code, such as ErrCodeMissingPermissions
+	Message    string `json:"message"` // Discord's description of the error
+	// Errors holds the errors for each invalid field of the request, nested like the
+	// request's JSON. FieldErrors lists them.
+	Errors json.RawMessage `json:"errors"`
+}
+
+// FieldError is an error for a single field of a REST request.
+type FieldError struct {
+	Field   string // The path to the field, such as "embed.fields.0.name"
+	Code    string // Such as "BASE_TYPE_MAX_LENGTH"
+	Message string
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "discord: %d %s", e.StatusCode, e.Message)
+	if e.Code != 0 {
+		fmt.Fprintf(&b, " (%d)", e.Code)
+	}
+	for _, fieldErr := range e.FieldErrors() {
+		fmt.Fprintf(&b, "; %s: %s", fieldErr.Field, fieldErr.Message)
+	}
+	return b.String()
+}
+
+// FieldErrors returns the errors in e.Errors, sorted by field.
+func (e *APIError) FieldErrors() []FieldError {
+	var tree map[string]json.RawMessage
+	if json.Unmarshal(e.Errors, &tree) != nil {
+		return nil
+	}
+
+	var fieldErrs []FieldError
+	appendFieldErrors(&fieldErrs, "", tree)
+	sort.SliceStable(fieldErrs, func(i, j int) bool {
+		return fieldErrs[i].Field < fieldErrs[j].Field
+	})
+	return fieldErrs
+}
+
+// appendFieldErrors flattens the errors under the field path into fieldErrs. Discord lists
+// a field's own errors under "_errors" and nests the errors of its members by name.
+func appendFieldErrors(fieldErrs *[]FieldError, path string, tree map[string]json.RawMessage) {
+	for key, value := range tree {
+		if key == "_errors" {
+			var errs []struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			_ = json.Unmarshal(value, &errs)
+			for _, err := range errs {
+				*fieldErrs = append(*fieldErrs, FieldError{Field: path, Code: err.Code, Message: err.Message})
+			}
+			continue
+		}
+
+		var subtree map[string]json.RawMessage
+		if json.Unmarshal(value, &subtree) == nil {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			appendFieldErrors(fieldErrs, field, subtree)
+		}
+	}
+}
+
+// IsNotFound reports whether err is an APIError for something that does not exist, such
+// as an unknown channel or message.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == 404 || apiErr.Code >= 10000 && apiErr.Code < 20000)
+}
+
+// IsMissingPermissions reports whether err is an APIError for a request the client lacks
+// the permissions for.
+func IsMissingPermissions(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == ErrCodeMissingPermissions
+}
@@ -0,0 +1,50 @@
+package discord
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body: ioutil.NopCloser(strings.NewReader(`{"code":50035,"message":"Invalid Form Body","errors":{` +
+			`"content":{"_errors":[{"code":"BASE_TYPE_MAX_LENGTH","message":"Must be 2000 or fewer in length."}]},` +
+			`"embed":{"fields":{"0":{"name":{"_errors":[{"code":"BASE_TYPE_REQUIRED","message":"This field is required"}]}}}}}}`)),
+	}
+
+	err := readResponse(resp, nil)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != ErrCodeInvalidFormBody {
+		t.Fatalf("got %#v", err)
+	}
+
+	want := []FieldError{
+		{Field: "content", Code: "BASE_TYPE_MAX_LENGTH", Message: "Must be 2000 or fewer in length."},
+		{Field: "embed.fields.0.name", Code: "BASE_TYPE_REQUIRED", Message: "This field is required"},
+	}
+	if got := apiErr.FieldErrors(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	wantMessage := "discord: 400 Invalid Form Body (50035); content: Must be 2000 or fewer in length.; embed.fields.0.name: This field is required"
+	if err.Error() != wantMessage {
+		t.Fatalf("got %q, want %q", err.Error(), wantMessage)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := &APIError{StatusCode: http.StatusNotFound, Code: ErrCodeUnknownChannel, Message: "Unknown Channel"}
+	forbidden := &APIError{StatusCode: http.StatusForbidden, Code: ErrCodeMissingPermissions, Message: "Missing Permissions"}
+
+	if !IsNotFound(notFound) || !IsNotFound(fmt.Errorf("getting channel: %w", notFound)) || IsNotFound(forbidden) {
+		t.Error("IsNotFound is wrong")
+	}
+	if !IsMissingPermissions(forbidden) || IsMissingPermissions(notFound) {
+		t.Error("IsMissingPermissions is wrong")
+	}
+}
@@ -0,0 +1,514 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// External Term Format tags for the subset of terms Discord uses
+const (
+	etfVersion       = 131
+	etfNewFloat      = 70
+	etfSmallInteger  = 97
+	etfInteger       = 98
+	etfAtom          = 100
+	etfNil           = 106
+	etfString        = 107
+	etfList          = 108
+	etfBinary        = 109
+	etfSmallBig      = 110
+	etfLargeBig      = 111
+	etfSmallAtom     = 115
+	etfMap           = 116
+	etfAtomUTF8      = 118
+	etfSmallAtomUTF8 = 119
+)
+
+// Deepest nesting of lists and maps the decoder accepts
+const etfMaxDepth = 256
+
+var (
+	errETFVersion   = errors.New("discord: unsupported ETF version")
+	errETFTruncated = errors.New("discord: truncated ETF term")
+	errETFTrailing  = errors.New("discord: trailing data after ETF term")
+	errETFDepth     = errors.New("discord: ETF term nested too deeply")
+)
+
+// etfCodec encodes payloads in Erlang's External Term Format
+type etfCodec struct{}
+
+func (etfCodec) encoding() Encoding {
+	return EncodingETF
+}
+
+func (etfCodec) messageType() int {
+	return websocket.BinaryMessage
+}
+
+// encode converts cmd to its JSON form first, so it is encoded exactly as jsonCodec
+// would encode it
+func (etfCodec) encode(cmd gatewayCommand) ([]byte, error) {
+	b, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var v interface{}
+	if err = d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return encodeETF(v)
+}
+
+// decode converts the payload's d term to JSON, so it is decoded into event types exactly
+// as a JSON payload would be
+func (etfCodec) decode(b []byte, op *gatewayOp) error {
+	v, err := decodeETF(b)
+	if err != nil {
+		return err
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("discord: gateway payload is not a map")
+	}
+
+	d, err := json.Marshal(m["d"])
+	if err != nil {
+		return err
+	}
+
+	*op = gatewayOp{D: d}
+	if n, ok := m["op"].(json.Number); ok {
+		i, _ := n.Int64()
+		op.Op = int(i)
+	}
+	if n, ok := m["s"].(json.Number); ok {
+		i, _ := n.Int64()
+		op.S = int(i)
+	}
+	if t, ok := m["t"].(string); ok {
+		op.T = GatewayEventType(t)
+	}
+	return nil
+}
+
+// decodeETF decodes a term into the types encoding/json uses: atoms become strings, except
+// nil, true and false; binaries become strings; integers of any size become json.Number, so
+// millisecond timestamps keep every digit, and lists become []interface{}. Snowflakes become
+// decimal strings, matching the string IDs of the JSON encoding.
+func decodeETF(b []byte) (interface{}, error) {
+	if len(b) == 0 || b[0] != etfVersion {
+		return nil, errETFVersion
+	}
+
+	d := etfDecoder{b: b[1:]}
+	v, err := d.term()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.b) != 0 {
+		return nil, errETFTrailing
+	}
+	return v, nil
+}
+
+// etfDecoder decodes terms from the front of b
+type etfDecoder struct {
+	b     []byte
+	depth int
+}
+
+// next consumes n bytes
+func (d *etfDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.b) < n {
+		return nil, errETFTruncated
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
+
+// length consumes a big endian length of size 1, 2 or 4 bytes
+func (d *etfDecoder) length(size int) (int, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b)), nil
+	default:
+		n := binary.BigEndian.Uint32(b)
+		if uint64(n) > uint64(len(d.b)) {
+			// Every element takes at least a byte, so this cannot be satisfied
+			return 0, errETFTruncated
+		}
+		return int(n), nil
+	}
+}
+
+// term decodes the next term
+func (d *etfDecoder) term() (interface{}, error) {
+	tag, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag[0] {
+	case etfSmallInteger:
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.Itoa(int(b[0]))), nil
+	case etfInteger:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.Itoa(int(int32(binary.BigEndian.Uint32(b))))), nil
+	case etfNewFloat:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		f := math.Float64frombits(binary.BigEndian.Uint64(b))
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errors.New("discord: invalid ETF float")
+		}
+		return f, nil
+	case etfAtom, etfAtomUTF8, etfSmallAtom, etfSmallAtomUTF8:
+		name, err := d.atom(tag[0])
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "nil":
+			return nil, nil
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return name, nil
+	case etfBinary:
+		n, err := d.length(4)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case etfSmallBig, etfLargeBig:
+		size := 1
+		if tag[0] == etfLargeBig {
+			size = 4
+		}
+		n, err := d.length(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.big(n)
+	case etfNil:
+		return []interface{}{}, nil
+	case etfString:
+		// Erlang sends lists of bytes this way
+		n, err := d.length(2)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, len(b))
+		for i, c := range b {
+			list[i] = json.Number(strconv.Itoa(int(c)))
+		}
+		return list, nil
+	case etfList:
+		return d.list()
+	case etfMap:
+		return d.m()
+	}
+	return nil, fmt.Errorf("discord: unsupported ETF tag %d", tag[0])
+}
+
+// atom decodes the name of an atom with the given tag
+func (d *etfDecoder) atom(tag byte) (string, error) {
+	size := 2
+	if tag == etfSmallAtom || tag == etfSmallAtomUTF8 {
+		size = 1
+	}
+	n, err := d.length(size)
+	if err != nil {
+		return "", err
+	}
+	b, err := d.next(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// big decodes the sign and n byte little endian magnitude of a big integer
+func (d *etfDecoder) big(n int) (interface{}, error) {
+	sign, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+
+	magnitude := make([]byte, n)
+	for i, c := range b {
+		magnitude[n-1-i] = c
+	}
+	i := new(big.Int).SetBytes(magnitude)
+	if sign[0] != 0 {
+		i.Neg(i)
+	}
+	return json.Number(i.String()), nil
+}
+
+// list decodes a proper list
+func (d *etfDecoder) list() (interface{}, error) {
+	n, err := d.length(4)
+	if err != nil {
+		return nil, err
+	}
+	if d.depth++; d.depth > etfMaxDepth {
+		return nil, errETFDepth
+	}
+	defer func() { d.depth-- }()
+
+	list := make([]interface{}, n)
+	for i := range list {
+		if list[i], err = d.term(); err != nil {
+			return nil, err
+		}
+	}
+
+	tail, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	if tail[0] != etfNil {
+		return nil, errors.New("discord: improper ETF list")
+	}
+	return list, nil
+}
+
+// m decodes a map. Keys must be atoms or binaries.
+func (d *etfDecoder) m() (interface{}, error) {
+	n, err := d.length(4)
+	if err != nil {
+		return nil, err
+	}
+	if d.depth++; d.depth > etfMaxDepth {
+		return nil, errETFDepth
+	}
+	defer func() { d.depth-- }()
+
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		if m[key], err = d.term(); err != nil {
+			return nil, err
+		}
+		if isSnowflakeKey(key) {
+			m[key] = snowflakes(m[key])
+		}
+	}
+	return m, nil
+}
+
+// isSnowflakeKey reports whether Discord uses key for a snowflake or a list of them
+func isSnowflakeKey(key string) bool {
+	switch key {
+	case "id", "ids", "roles", "mention_roles", "not_found":
+		return true
+	}
+	return strings.HasSuffix(key, "_id") || strings.HasSuffix(key, "_ids")
+}
+
+// snowflakes turns the integers in v, or in v's elements if it is a list, into decimal
+// strings. Anything else, such as a list of role objects, is left alone.
+func snowflakes(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		return string(v)
+	case []interface{}:
+		for i, e := range v {
+			if n, ok := e.(json.Number); ok {
+				v[i] = string(n)
+			}
+		}
+	}
+	return v
+}
+
+// key decodes a map key
+func (d *etfDecoder) key() (string, error) {
+	tag, err := d.next(1)
+	if err != nil {
+		return "", err
+	}
+
+	switch tag[0] {
+	case etfAtom, etfAtomUTF8, etfSmallAtom, etfSmallAtomUTF8:
+		return d.atom(tag[0])
+	case etfBinary:
+		n, err := d.length(4)
+		if err != nil {
+			return "", err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("discord: unsupported ETF map key tag %d", tag[0])
+}
+
+// encodeETF encodes a value of the types encoding/json decodes into, with json.Number
+// for numbers or float64. Strings are encoded as binaries.
+func encodeETF(v interface{}) ([]byte, error) {
+	return appendETF([]byte{etfVersion}, v)
+}
+
+// appendETF appends the term for v to b
+func appendETF(b []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return appendAtom(b, "nil"), nil
+	case bool:
+		if v {
+			return appendAtom(b, "true"), nil
+		}
+		return appendAtom(b, "false"), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendInteger(b, i), nil
+		}
+		if i, ok := new(big.Int).SetString(string(v), 10); ok {
+			return appendBig(b, i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return appendETF(b, f)
+	case float64:
+		// Integers are sent as json.Number, so a float64 is always sent as a float
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errors.New("discord: cannot encode non-finite float as ETF")
+		}
+		b = append(b, etfNewFloat)
+		return appendUint64(b, math.Float64bits(v)), nil
+	case string:
+		b = append(b, etfBinary)
+		b = appendUint32(b, uint32(len(v)))
+		return append(b, v...), nil
+	case []interface{}:
+		if len(v) == 0 {
+			return append(b, etfNil), nil
+		}
+		b = append(b, etfList)
+		b = appendUint32(b, uint32(len(v)))
+		var err error
+		for _, e := range v {
+			if b, err = appendETF(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, etfNil), nil
+	case map[string]interface{}:
+		b = append(b, etfMap)
+		b = appendUint32(b, uint32(len(v)))
+		var err error
+		for key, value := range v {
+			if b, err = appendETF(b, key); err != nil {
+				return nil, err
+			}
+			if b, err = appendETF(b, value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("discord: cannot encode %T as ETF", v)
+}
+
+// appendAtom appends an atom named name, which must be shorter than 256 bytes
+func appendAtom(b []byte, name string) []byte {
+	b = append(b, etfSmallAtomUTF8, byte(len(name)))
+	return append(b, name...)
+}
+
+// appendInteger appends i in the smallest integer term that holds it
+func appendInteger(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= math.MaxUint8:
+		return append(b, etfSmallInteger, byte(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		b = append(b, etfInteger)
+		return appendUint32(b, uint32(int32(i)))
+	}
+
+	return appendBig(b, big.NewInt(i))
+}
+
+// appendBig appends i as a big integer term
+func appendBig(b []byte, i *big.Int) []byte {
+	var sign byte
+	if i.Sign() < 0 {
+		sign = 1
+	}
+
+	// The magnitude is little endian
+	digits := new(big.Int).Abs(i).Bytes()
+	for l, r := 0, len(digits)-1; l < r; l, r = l+1, r-1 {
+		digits[l], digits[r] = digits[r], digits[l]
+	}
+
+	if len(digits) <= math.MaxUint8 {
+		b = append(b, etfSmallBig, byte(len(digits)), sign)
+	} else {
+		b = append(b, etfLargeBig)
+		b = appendUint32(b, uint32(len(digits)))
+		b = append(b, sign)
+	}
+	return append(b, digits...)
+}
+
+func appendUint32(b []byte, n uint32) []byte {
+	return append(b, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func appendUint64(b []byte, n uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(n>>32)), uint32(n))
+}
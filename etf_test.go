@@ -0,0 +1,135 @@
+package discord
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestETFCodec(t *testing.T) {
+	codec := etfCodec{}
+
+	b, err := codec.encode(gatewayCommand{Op: opIdentify, D: identifyData{Token: "token", Intents: IntentGuildMessages}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var op gatewayOp
+	if err = codec.decode(b, &op); err != nil {
+		t.Fatal(err)
+	}
+	if op.Op != opIdentify {
+		t.Fatalf("got op %v, want %v", op.Op, opIdentify)
+	}
+	var d identifyData
+	if err = json.Unmarshal(op.D, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Token != "token" || d.Intents != IntentGuildMessages {
+		t.Fatalf("got %+v", d)
+	}
+}
+
+func TestETFDecode(t *testing.T) {
+	// A dispatch as Discord sends it: atom keys, and a snowflake as a small big integer
+	b := []byte{
+		etfVersion, etfMap, 0, 0, 0, 4,
+		etfSmallAtom, 2, 'o', 'p', etfSmallInteger, 0,
+		etfSmallAtom, 1, 's', etfInteger, 0, 0, 1, 0,
+		etfSmallAtom, 1, 't', etfAtom, 0, 5, 'R', 'E', 'A', 'D', 'Y',
+		etfSmallAtom, 1, 'd', etfMap, 0, 0, 0, 3,
+		etfSmallAtom, 2, 'i', 'd', etfSmallBig, 8, 0, 0x00, 0x80, 0x72, 0xf0, 0xb8, 0x15, 0xb1, 0x08,
+		etfBinary, 0, 0, 0, 4, 'n', 'a', 'm', 'e', etfBinary, 0, 0, 0, 3, 'b', 'o', 't',
+		etfSmallAtom, 5, 'r', 'o', 'l', 'e', 's', etfNil,
+	}
+
+	var op gatewayOp
+	if err := (etfCodec{}).decode(b, &op); err != nil {
+		t.Fatal(err)
+	}
+
+	want := gatewayOp{
+		Op: opDispatch,
+		S:  256,
+		T:  GatewayReady,
+		D:  json.RawMessage(`{"id":"626305707233411072","name":"bot","roles":[]}`),
+	}
+	if !reflect.DeepEqual(op, want) {
+		t.Fatalf("got %#v, want %#v", op, want)
+	}
+}
+
+func TestETFSnowflakes(t *testing.T) {
+	// Discord sends snowflakes and timestamps as big integers
+	b, err := encodeETF(map[string]interface{}{
+		"user":     map[string]interface{}{"id": json.Number("626305707233411072")},
+		"guild_id": json.Number("626305707233411073"),
+		"roles":    []interface{}{json.Number("626305707233411074")},
+		"game": map[string]interface{}{
+			"type":       json.Number("0"),
+			"timestamps": map[string]interface{}{"start": json.Number("1571863853912")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := decodeETF(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"user":     map[string]interface{}{"id": "626305707233411072"},
+		"guild_id": "626305707233411073",
+		"roles":    []interface{}{"626305707233411074"},
+		"game": map[string]interface{}{
+			"type":       json.Number("0"),
+			"timestamps": map[string]interface{}{"start": json.Number("1571863853912")},
+		},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("got %#v, want %#v", v, want)
+	}
+}
+
+func FuzzETF(f *testing.F) {
+	for _, v := range []interface{}{
+		nil,
+		true,
+		json.Number("7"),
+		json.Number("-70000"),
+		json.Number("-36893488147419103232"),
+		1.5,
+		"snowflake",
+		[]interface{}{json.Number("1"), "two", []interface{}{}},
+		map[string]interface{}{"op": json.Number("0"), "d": map[string]interface{}{"id": json.Number("626305707233411072")}},
+	} {
+		b, err := encodeETF(v)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Add([]byte{etfVersion, etfSmallBig, 8, 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	f.Add([]byte{etfVersion, etfString, 0, 3, 1, 2, 3})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		v, err := decodeETF(b)
+		if err != nil {
+			return
+		}
+
+		encoded, err := encodeETF(v)
+		if err != nil {
+			t.Fatalf("encoding %#v: %v", v, err)
+		}
+
+		decoded, err := decodeETF(encoded)
+		if err != nil {
+			t.Fatalf("decoding %x: %v", encoded, err)
+		}
+		if !reflect.DeepEqual(v, decoded) {
+			t.Fatalf("round trip changed %#v to %#v", v, decoded)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package discord
+
+import "time"
+
+// Ready is the payload of a READY event.
+type Ready struct {
+	Version         int                `json:"v"`
+	User            User               `json:"user"`
+	PrivateChannels []Channel          `json:"private_channels"`
+	Guilds          []UnavailableGuild `json:"guilds"`
+	SessionID       string             `json:"session_id"`
+	Shard           [2]int             `json:"shard"`
+}
+
+// UnavailableGuild is a guild whose data is not available, either because the client has
+// just connected or because of an outage. It is also the payload of GUILD_DELETE, where
+// Unavailable is false if the client was removed from the guild.
+type UnavailableGuild struct {
+	ID          string `json:"id"`
+	Unavailable bool   `json:"unavailable"`
+}
+
+// Guild is the Go representation of Guild in the Discord API.
+type Guild struct {
+	ID                          string        `json:"id"`
+	Name                        string        `json:"name"`
+	Icon                        string        `json:"icon"`
+	Splash                      string        `json:"splash"`
+	OwnerID                     string        `json:"owner_id"`
+	Region                      string        `json:"region"`
+	AFKChannelID                string        `json:"afk_channel_id"`
+	AFKTimeout                  int           `json:"afk_timeout"`
+	VerificationLevel           int           `json:"verification_level"`
+	DefaultMessageNotifications int           `json:"default_message_notifications"`
+	ExplicitContentFilter       int           `json:"explicit_content_filter"`
+	Roles                       []Role        `json:"roles"`
+	Emojis                      []Emoji       `json:"emojis"`
+	Features                    []string      `json:"features"`
+	MFALevel                    int           `json:"mfa_level"`
+	ApplicationID               string        `json:"application_id"`
+	SystemChannelID             string        `json:"system_channel_id"`
+	JoinedAt                    time.Time     `json:"joined_at"`
+	Large                       bool          `json:"large"`
+	Unavailable                 bool          `json:"unavailable"`
+	MemberCount                 int           `json:"member_count"`
+	VoiceStates                 []VoiceState  `json:"voice_states"`
+	Members                     []GuildMember `json:"members"`
+	Channels                    []Channel     `json:"channels"`
+	Presences                   []Presence    `json:"presences"`
+	Description                 string        `json:"description"`
+	Banner                      string        `json:"banner"`
+	PremiumTier                 int           `json:"premium_tier"`
+	PremiumSubscriptionCount    int           `json:"premium_subscription_count"`
+	PreferredLocale             string        `json:"preferred_locale"`
+}
+
+// VoiceState is the Go representation of VoiceState in the Discord API.
+type VoiceState struct {
+	GuildID   string       `json:"guild_id"`
+	ChannelID string       `json:"channel_id"`
+	UserID    string       `json:"user_id"`
+	Member    *GuildMember `json:"member"`
+	SessionID string       `json:"session_id"`
+	Deaf      bool         `json:"deaf"`
+	Mute      bool         `json:"mute"`
+	SelfDeaf  bool         `json:"self_deaf"`
+	SelfMute  bool         `json:"self_mute"`
+	Suppress  bool         `json:"suppress"`
+}
+
+// ChannelPinsUpdate is the payload of a CHANNEL_PINS_UPDATE event.
+type ChannelPinsUpdate struct {
+	GuildID          string    `json:"guild_id"`
+	ChannelID        string    `json:"channel_id"`
+	LastPinTimestamp time.Time `json:"last_pin_timestamp"`
+}
+
+// GuildBan is the payload of GUILD_BAN_ADD and GUILD_BAN_REMOVE events.
+type GuildBan struct {
+	GuildID string `json:"guild_id"`
+	User    User   `json:"user"`
+}
+
+// GuildEmojisUpdate is the payload of a GUILD_EMOJIS_UPDATE event.
+type GuildEmojisUpdate struct {
+	GuildID string  `json:"guild_id"`
+	Emojis  []Emoji `json:"emojis"`
+}
+
+// GuildIntegrationsUpdate is the payload of a GUILD_INTEGRATIONS_UPDATE event.
+type GuildIntegrationsUpdate struct {
+	GuildID string `json:"guild_id"`
+}
+
+// GuildMemberAdd is the payload of a GUILD_MEMBER_ADD event.
+type GuildMemberAdd struct {
+	GuildMember
+	GuildID string `json:"guild_id"`
+}
+
+// GuildMemberRemove is the payload of a GUILD_MEMBER_REMOVE event.
+type GuildMemberRemove struct {
+	GuildID string `json:"guild_id"`
+	User    User   `json:"user"`
+}
+
+// GuildRole is the payload of GUILD_ROLE_CREATE and GUILD_ROLE_UPDATE events.
+type GuildRole struct {
+	GuildID string `json:"guild_id"`
+	Role    Role   `json:"role"`
+}
+
+// GuildRoleDelete is the payload of a GUILD_ROLE_DELETE event.
+type GuildRoleDelete struct {
+	GuildID string `json:"guild_id"`
+	RoleID  string `json:"role_id"`
+}
+
+// MessageDelete is the payload of a MESSAGE_DELETE event.
+type MessageDelete struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+}
+
+// MessageDeleteBulk is the payload of a MESSAGE_DELETE_BULK event.
+type MessageDeleteBulk struct {
+	IDs       []string `json:"ids"`
+	ChannelID string   `json:"channel_id"`
+	GuildID   string   `json:"guild_id"`
+}
+
+// MessageReaction is the payload of MESSAGE_REACTION_ADD and MESSAGE_REACTION_REMOVE events.
+type MessageReaction struct {
+	UserID    string       `json:"user_id"`
+	ChannelID string       `json:"channel_id"`
+	MessageID string       `json:"message_id"`
+	GuildID   string       `json:"guild_id"`
+	Member    *GuildMember `json:"member"`
+	Emoji     Emoji        `json:"emoji"`
+}
+
+// MessageReactionRemoveAll is the payload of a MESSAGE_REACTION_REMOVE_ALL event.
+type MessageReactionRemoveAll struct {
+	ChannelID string `json:"channel_id"`
+	MessageID string `json:"message_id"`
+	GuildID   string `json:"guild_id"`
+}
+
+// TypingStart is the payload of a TYPING_START event.
+type TypingStart struct {
+	ChannelID string       `json:"channel_id"`
+	GuildID   string       `json:"guild_id"`
+	UserID    string       `json:"user_id"`
+	Timestamp int64        `json:"timestamp"` // Unix time in seconds
+	Member    *GuildMember `json:"member"`
+}
+
+// VoiceServerUpdate is the payload of a VOICE_SERVER_UPDATE event.
+type VoiceServerUpdate struct {
+	Token    string `json:"token"`
+	GuildID  string `json:"guild_id"`
+	Endpoint string `json:"endpoint"`
+}
+
+// WebhooksUpdate is the payload of a WEBHOOKS_UPDATE event.
+type WebhooksUpdate struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+}
@@ -1,8 +1,12 @@
 package discord_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/miniriley2012/discord"
+	"log"
+	"time"
 )
 
 func ExampleClient_Listen() {
@@ -19,12 +23,176 @@ func ExampleClient_Listen() {
 	}
 }
 
+func ExampleClient_ListenContext() {
+	client := discord.NewClient("TOKEN")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	if err := client.ConnectContext(ctx); err != nil {
+		panic(err)
+	}
+
+	// Listen until the hour is up, then close the client
+	if err := client.ListenContext(ctx); err != nil && err != context.DeadlineExceeded {
+		panic(err)
+	}
+}
+
 func ExampleClient_Handle() {
 	client.Handle(discord.GatewayMessageCreate, discord.MessageHandler(func(client *discord.Client, message discord.Message) {
 		fmt.Printf("%v: %v\n", message.Author.Username, message.Content)
 	}))
 }
 
+func ExampleClient_OnError() {
+	client.OnError(func(client *discord.Client, eventType discord.GatewayEventType, data json.RawMessage, err error) {
+		log.Printf("handling %v: %v", eventType, err)
+	})
+}
+
+func ExampleClient_Use() {
+	// Log how long every handler takes, and leave out bots and other guilds
+	client.Use(func(next discord.EventHandler) discord.EventHandler {
+		return discord.EventHandlerFunc(func(client *discord.Client, data json.RawMessage) error {
+			defer func(start time.Time) {
+				log.Printf("handled in %v", time.Since(start))
+			}(time.Now())
+			return next.Handle(client, data)
+		})
+	}, discord.IgnoreBots, discord.OnlyGuilds("626305707233411072"))
+}
+
+func ExampleWithHandlerIntents() {
+	// Only receive the events there are handlers for
+	client := discord.NewClient("TOKEN", discord.WithHandlerIntents())
+
+	client.Handle(discord.GatewayMessageCreate, discord.MessageHandler(func(client *discord.Client, message discord.Message) {
+		fmt.Printf("%v: %v\n", message.Author.Username, message.Content)
+	}))
+
+	if err := client.Connect(); err != nil {
+		panic(err)
+	}
+}
+
+func ExampleWithWorkerPool() {
+	// Handle events on 8 goroutines, so a slow handler does not hold up the gateway.
+	// Messages in the same channel are still handled in order.
+	client := discord.NewClient("TOKEN", discord.WithWorkerPool(8))
+
+	client.Handle(discord.GatewayMessageCreate, discord.MessageHandler(func(client *discord.Client, message discord.Message) {
+		time.Sleep(time.Second)
+		fmt.Printf("%v: %v\n", message.Author.Username, message.Content)
+	}))
+
+	if err := client.Connect(); err != nil {
+		panic(err)
+	}
+}
+
+func ExampleClient_Events() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	events := client.Events(ctx, discord.GatewayMessageCreate, discord.GatewayMessageDelete)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			switch v := e.Value.(type) {
+			case discord.Message:
+				fmt.Printf("%v: %v\n", v.Author.Username, v.Content)
+			case discord.MessageDelete:
+				fmt.Printf("deleted %v\n", v.ID)
+			}
+		case <-ticker.C:
+			fmt.Println("tick")
+		}
+	}
+}
+
+func ExampleClient_WaitFor() {
+	client.Handle(discord.GatewayMessageCreate, discord.MessageHandler(func(client *discord.Client, message discord.Message) {
+		if message.Content != "!color" {
+			return
+		}
+		channel, err := client.GetChannel(message.ChannelID)
+		if err != nil {
+			return
+		}
+		_ = channel.Send("What is your favourite color?")
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Wait for the same user to answer in the same channel
+		reply, err := client.WaitFor(ctx, discord.GatewayMessageCreate, func(e discord.Event) bool {
+			m := e.Value.(discord.Message)
+			return m.Author.ID == message.Author.ID && m.ChannelID == message.ChannelID
+		})
+		if err != nil {
+			_ = channel.Send("Too slow!")
+			return
+		}
+		_ = channel.Send("Mine is " + reply.Value.(discord.Message).Content + " too")
+	}))
+}
+
+func ExampleChannel_SendMessage() {
+	client.Handle(discord.GatewayMessageCreate, discord.MessageHandler(func(client *discord.Client, message discord.Message) {
+		if message.Content != "!ping" {
+			return
+		}
+		channel, err := client.GetChannel(message.ChannelID)
+		if err != nil {
+			return
+		}
+
+		// Reply without notifying anyone
+		_, err = channel.SendMessage(context.Background(), discord.MessageSend{
+			Content:          "Pong!",
+			AllowedMentions:  &discord.AllowedMentions{},
+			MessageReference: &discord.MessageReference{MessageID: message.ID},
+		})
+		if discord.IsMissingPermissions(err) {
+			log.Printf("cannot send messages in %v", channel.Name)
+		}
+	}))
+}
+
+func ExampleClient_SetActivity() {
+	err := client.SetActivity(context.Background(), discord.Activity{
+		Name: "with Go",
+		Type: discord.ActivityGame,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleShardManager() {
+	manager := discord.NewShardManager("TOKEN")
+
+	manager.Handle(discord.GatewayMessageCreate, discord.MessageHandler(func(client *discord.Client, message discord.Message) {
+		shard, _ := client.Shard()
+		fmt.Printf("[shard %v] %v: %v\n", shard, message.Author.Username, message.Content)
+	}))
+
+	if err := manager.Connect(); err != nil {
+		panic(err)
+	}
+
+	if err := manager.Listen(); err != nil {
+		panic(err)
+	}
+}
+
 func Example() {
 	client := discord.NewClient("TOKEN")
 
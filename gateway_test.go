@@ -0,0 +1,317 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubGateway is a gateway server for tests. It says Hello on each new connection and
+// hands the connection to the test through conns.
+type stubGateway struct {
+	*httptest.Server
+	conns chan *stubConn
+}
+
+// stubConn is the server side of a gateway connection
+type stubConn struct {
+	t   *testing.T
+	ws  *websocket.Conn
+	ack bool
+}
+
+// stubOp is a payload sent by the client
+type stubOp struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d"`
+}
+
+func newStubGateway(t *testing.T, heartbeatInterval int) *stubGateway {
+	g := &stubGateway{conns: make(chan *stubConn, 8)}
+	upgrader := websocket.Upgrader{}
+	g.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn := &stubConn{t: t, ws: ws, ack: true}
+		conn.send(opHello, "", 0, helloData{HeartbeatInterval: heartbeatInterval})
+		g.conns <- conn
+	}))
+	return g
+}
+
+// client returns a client that connects to the stub gateway
+func (g *stubGateway) client(options ...ClientOption) *Client {
+	url := "ws" + strings.TrimPrefix(g.URL, "http")
+	return NewClient("token", append([]ClientOption{withGatewayURL(url)}, options...)...)
+}
+
+// next waits for the next connection to the stub gateway
+func (g *stubGateway) next() *stubConn {
+	select {
+	case conn := <-g.conns:
+		return conn
+	case <-time.After(10 * time.Second):
+		panic("no connection to the stub gateway")
+	}
+}
+
+// connect connects c, makes its session ready at sequence 1 and starts listening. It
+// returns the server side of the connection and the result of Listen.
+func (g *stubGateway) connect(t *testing.T, c *Client) (*stubConn, <-chan error) {
+	connected := make(chan error, 1)
+	go func() { connected <- c.Connect() }()
+	conn := g.next()
+	conn.ready(1)
+	if err := <-connected; err != nil {
+		t.Fatal(err)
+	}
+
+	listening := make(chan error, 1)
+	go func() { listening <- c.Listen() }()
+	return conn, listening
+}
+
+// send writes a payload to the client
+func (c *stubConn) send(op int, t GatewayEventType, s int, d interface{}) {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		c.t.Error(err)
+		return
+	}
+	packet := gatewayOp{Op: op, D: raw, S: s, T: t}
+	if err = c.ws.WriteJSON(packet); err != nil {
+		c.t.Error(err)
+	}
+}
+
+// read returns the next payload from the client that is not a heartbeat, acknowledging
+// heartbeats as they arrive if c.ack is set
+func (c *stubConn) read() stubOp {
+	for {
+		var op stubOp
+		_ = c.ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+		if err := c.ws.ReadJSON(&op); err != nil {
+			c.t.Fatal(err)
+		}
+		if op.Op != opHeartbeat {
+			return op
+		}
+		if c.ack {
+			c.send(opHeartbeatACK, "", 0, nil)
+		}
+	}
+}
+
+// expect reads the next payload and fails the test unless it has the given op code
+func (c *stubConn) expect(op int, v interface{}) {
+	got := c.read()
+	if got.Op != op {
+		c.t.Fatalf("got op %d %s, want op %d", got.Op, got.D, op)
+	}
+	if v != nil {
+		if err := json.Unmarshal(got.D, v); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}
+
+// ready identifies the client and sends READY with session id "session"
+func (c *stubConn) ready(s int) {
+	c.expect(opIdentify, nil)
+	c.send(opDispatch, GatewayReady, s, map[string]interface{}{
+		"session_id": "session",
+		"user":       map[string]string{"id": "1", "username": "bot"},
+	})
+}
+
+// resumed expects the client to resume at seq and confirms it
+func (c *stubConn) resumed(seq int) {
+	var data resumeData
+	c.expect(opResume, &data)
+	if data.SessionID != "session" || data.Sequence != seq {
+		c.t.Fatalf("resumed session %q at %d, want %q at %d", data.SessionID, data.Sequence, "session", seq)
+	}
+	c.send(opDispatch, GatewayResumed, seq, struct{}{})
+}
+
+func TestReconnect(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client()
+	conn, listening := g.connect(t, c)
+
+	// Reconnect asked for by Discord
+	conn.send(opDispatch, GatewayTypingStart, 2, struct{}{})
+	conn.send(opReconnect, "", 0, nil)
+	conn = g.next()
+	conn.resumed(2)
+
+	// Dropped connection
+	conn.send(opDispatch, GatewayTypingStart, 3, struct{}{})
+	_ = conn.ws.Close()
+	conn = g.next()
+	conn.resumed(3)
+
+	// Closed for a reason reconnecting cannot fix
+	_ = conn.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(CloseAuthenticationFailed, "Authentication failed."))
+
+	select {
+	case err := <-listening:
+		var closeErr *GatewayCloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != CloseAuthenticationFailed {
+			t.Fatalf("Listen returned %v, want a *GatewayCloseError with code %d", err, CloseAuthenticationFailed)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Listen did not return")
+	}
+}
+
+func TestInvalidSession(t *testing.T) {
+	defer func(wait func() time.Duration) { invalidSessionWait = wait }(invalidSessionWait)
+	invalidSessionWait = func() time.Duration { return 0 }
+
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client()
+	conn, listening := g.connect(t, c)
+
+	// A resumable session is resumed on the same connection
+	conn.send(opDispatch, GatewayTypingStart, 2, struct{}{})
+	conn.send(opInvalidSession, "", 0, true)
+	conn.resumed(2)
+
+	// Otherwise the client identifies again
+	conn.send(opInvalidSession, "", 0, false)
+	conn.ready(1)
+
+	_ = c.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMissedHeartbeatACK(t *testing.T) {
+	g := newStubGateway(t, 50)
+	defer g.Close()
+	c := g.client()
+	conn, listening := g.connect(t, c)
+
+	// Without an ACK the connection is a zombie, so the client reconnects and resumes
+	conn.send(opDispatch, GatewayTypingStart, 2, struct{}{})
+	conn.ack = false
+	conn = g.next()
+	conn.resumed(2)
+
+	_ = c.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestedHeartbeat(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client()
+	conn, listening := g.connect(t, c)
+
+	// heartbeat asks for a heartbeat and reads it without acknowledging it
+	heartbeat := func() {
+		conn.send(opHeartbeat, "", 0, nil)
+		var op struct {
+			Op int `json:"op"`
+			D  int `json:"d"`
+		}
+		if err := conn.ws.ReadJSON(&op); err != nil {
+			t.Fatal(err)
+		}
+		if op.Op != opHeartbeat || op.D != 1 {
+			t.Fatalf("got op %d with seq %d, want a heartbeat with seq 1", op.Op, op.D)
+		}
+	}
+	heartbeat()
+	conn.send(opHeartbeatACK, "", 0, nil)
+	// The ACK has been processed once the next request is answered
+	heartbeat()
+
+	// Heartbeats requested by Discord are not timed
+	if l := c.LatencyHistory(); len(l) != 0 {
+		t.Errorf("got latencies %v", l)
+	}
+
+	_ = c.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConnectAfterClose(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client(WithWorkerPool(1))
+	_, stopped := g.connect(t, c)
+	_ = c.Close()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+
+	connected := make(chan error, 1)
+	go func() { connected <- c.Connect() }()
+	conn := g.next()
+	conn.resumed(1)
+	if err := <-connected; err != nil {
+		t.Fatal(err)
+	}
+
+	typing := make(chan struct{})
+	c.HandleOnce(GatewayTypingStart, EventHandlerFunc(func(*Client, json.RawMessage) error {
+		close(typing)
+		return nil
+	}))
+	listening := make(chan error, 1)
+	go func() { listening <- c.Listen() }()
+
+	conn.send(opDispatch, GatewayTypingStart, 2, struct{}{})
+	select {
+	case <-typing:
+	case <-time.After(10 * time.Second):
+		t.Fatal("handler did not run after reconnecting")
+	}
+
+	_ = c.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListenErrorCloses(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client()
+	conn, listening := g.connect(t, c)
+
+	conn.send(opDispatch, GatewayReady, 2, map[string]interface{}{"session_id": 5})
+	if err := <-listening; err == nil {
+		t.Fatal("Listen returned nil for a READY it could not decode")
+	}
+	if !c.isClosed() {
+		t.Error("Listen returned without closing the client")
+	}
+
+	_ = conn.ws.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		if _, _, err := conn.ws.ReadMessage(); err != nil {
+			if _, ok := err.(*websocket.CloseError); !ok {
+				t.Errorf("got %v, want a close frame", err)
+			}
+			break
+		}
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/miniriley2012/discord
 
-go 1.12
+go 1.18
 
 require (
 	github.com/gorilla/websocket v1.4.0
@@ -0,0 +1,71 @@
+package discord
+
+// Intents is a set of gateway intents, which select the events Discord sends to a client.
+type Intents int
+
+// Gateway intents
+const (
+	IntentGuilds Intents = 1 << iota
+	IntentGuildMembers
+	IntentGuildBans
+	IntentGuildEmojis
+	IntentGuildIntegrations
+	IntentGuildWebhooks
+	IntentGuildInvites
+	IntentGuildVoiceStates
+	IntentGuildPresences
+	IntentGuildMessages
+	IntentGuildMessageReactions
+	IntentGuildMessageTyping
+	IntentDirectMessages
+	IntentDirectMessageReactions
+	IntentDirectMessageTyping
+)
+
+// eventIntents maps events to the intents that cause them to be sent. Events that are
+// missing are always sent.
+var eventIntents = map[GatewayEventType]Intents{
+	GatewayGuildCreate:              IntentGuilds,
+	GatewayGuildUpdate:              IntentGuilds,
+	GatewayGuildDelete:              IntentGuilds,
+	GatewayGuildRoleCreate:          IntentGuilds,
+	GatewayGuildRoleUpdate:          IntentGuilds,
+	GatewayGuildRoleDelete:          IntentGuilds,
+	GatewayChannelCreate:            IntentGuilds,
+	GatewayChannelUpdate:            IntentGuilds,
+	GatewayChannelDelete:            IntentGuilds,
+	GatewayChannelPinsUpdate:        IntentGuilds | IntentDirectMessages,
+	GatewayGuildMemberAdd:           IntentGuildMembers,
+	GatewayGuildMemberUpdate:        IntentGuildMembers,
+	GatewayGuildMemberRemove:        IntentGuildMembers,
+	GatewayGuildBanAdd:              IntentGuildBans,
+	GatewayGuildBanRemove:           IntentGuildBans,
+	GatewayGuildEmojisUpdate:        IntentGuildEmojis,
+	GatewayGuildIntegrationsUpdate:  IntentGuildIntegrations,
+	GatewayWebhooksUpdate:           IntentGuildWebhooks,
+	GatewayVoiceStateUpdate:         IntentGuildVoiceStates,
+	GatewayPresenceUpdate:           IntentGuildPresences,
+	GatewayMessageCreate:            IntentGuildMessages | IntentDirectMessages,
+	GatewayMessageUpdate:            IntentGuildMessages | IntentDirectMessages,
+	GatewayMessageDelete:            IntentGuildMessages | IntentDirectMessages,
+	GatewayMessageDeleteBulk:        IntentGuildMessages,
+	GatewayMessageReactionAdd:       IntentGuildMessageReactions | IntentDirectMessageReactions,
+	GatewayMessageReactionRemove:    IntentGuildMessageReactions | IntentDirectMessageReactions,
+	GatewayMessageReactionRemoveAll: IntentGuildMessageReactions | IntentDirectMessageReactions,
+	GatewayTypingStart:              IntentGuildMessageTyping | IntentDirectMessageTyping,
+}
+
+// IntentsFor returns the intents needed to receive every one of eventTypes, in guilds
+// as well as direct messages.
+func IntentsFor(eventTypes ...GatewayEventType) Intents {
+	var intents Intents
+	for _, t := range eventTypes {
+		intents |= eventIntents[t]
+	}
+	return intents
+}
+
+// Has reports whether all of other are set in i.
+func (i Intents) Has(other Intents) bool {
+	return i&other == other
+}
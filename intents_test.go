@@ -0,0 +1,54 @@
+package discord
+
+import "testing"
+
+func TestIntentsFor(t *testing.T) {
+	tests := []struct {
+		eventTypes []GatewayEventType
+		intents    Intents
+	}{
+		{nil, 0},
+		{[]GatewayEventType{GatewayReady}, 0},
+		{[]GatewayEventType{GatewayGuildCreate}, IntentGuilds},
+		{[]GatewayEventType{GatewayMessageCreate}, IntentGuildMessages | IntentDirectMessages},
+		{[]GatewayEventType{GatewayMessageDeleteBulk, GatewayPresenceUpdate}, IntentGuildMessages | IntentGuildPresences},
+		{[]GatewayEventType{GatewayTypingStart, GatewayReady}, IntentGuildMessageTyping | IntentDirectMessageTyping},
+	}
+
+	for _, test := range tests {
+		if intents := IntentsFor(test.eventTypes...); intents != test.intents {
+			t.Errorf("%v: got %b, want %b", test.eventTypes, intents, test.intents)
+		}
+
+		c := NewClient("")
+		for _, eventType := range test.eventTypes {
+			c.Handle(eventType, MessageHandler(func(*Client, Message) {}))
+		}
+		if intents := c.HandlerIntents(); intents != test.intents {
+			t.Errorf("%v: got handler intents %b, want %b", test.eventTypes, intents, test.intents)
+		}
+	}
+}
+
+func TestWithHandlerIntents(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client(WithIntents(IntentGuilds), WithHandlerIntents())
+	c.Handle(GatewayMessageReactionAdd, MessageHandler(func(*Client, Message) {}))
+
+	connected := make(chan error, 1)
+	go func() { connected <- c.Connect() }()
+	conn := g.next()
+
+	var data identifyData
+	conn.expect(opIdentify, &data)
+	if want := IntentGuilds | IntentGuildMessageReactions | IntentDirectMessageReactions; data.Intents != want {
+		t.Errorf("identified with intents %b, want %b", data.Intents, want)
+	}
+
+	conn.send(opDispatch, GatewayReady, 1, map[string]string{"session_id": "session"})
+	if err := <-connected; err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Close()
+}
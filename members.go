@@ -0,0 +1,109 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+// GuildMembersChunk is a part of the response to a Request Guild Members.
+type GuildMembersChunk struct {
+	GuildID    string        `json:"guild_id"`
+	Members    []GuildMember `json:"members"`
+	ChunkIndex int           `json:"chunk_index"`
+	ChunkCount int           `json:"chunk_count"`
+	NotFound   []string      `json:"not_found"`
+	Presences  []Presence    `json:"presences"`
+	Nonce      string        `json:"nonce"`
+}
+
+type requestGuildMembersData struct {
+	GuildID   string   `json:"guild_id"`
+	Query     *string  `json:"query,omitempty"`
+	Limit     int      `json:"limit"`
+	Presences bool     `json:"presences"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	Nonce     string   `json:"nonce"`
+}
+
+// memberRequest collects the chunks answering a Request Guild Members
+type memberRequest struct {
+	members  []GuildMember
+	received int
+	done     chan struct{}
+}
+
+// RequestGuildMembers requests members of a guild from the gateway and waits for all of
+// the chunks Discord answers with, or until ctx is done. Members are found either by
+// userIDs or, if there are none, by usernames starting with query, with an empty query
+// matching every member. A limit of 0 returns every match. Presences of the members are
+// included in the GUILD_MEMBERS_CHUNK events passed to handlers if presences is true.
+//
+// Chunks are collected as Listen reads them, so RequestGuildMembers can be called from a
+// handler, as described for WaitFor.
+func (c *Client) RequestGuildMembers(ctx context.Context, guildID, query string, limit int, userIDs []string, presences bool) ([]GuildMember, error) {
+	req := &memberRequest{done: make(chan struct{})}
+	closed := c.clientContext().Done()
+
+	c.requestsMu.Lock()
+	c.nonce++
+	nonce := strconv.Itoa(c.nonce)
+	c.memberRequests[nonce] = req
+	c.requestsMu.Unlock()
+
+	defer func() {
+		c.requestsMu.Lock()
+		delete(c.memberRequests, nonce)
+		c.requestsMu.Unlock()
+	}()
+
+	data := requestGuildMembersData{
+		GuildID:   guildID,
+		Limit:     limit,
+		Presences: presences,
+		UserIDs:   userIDs,
+		Nonce:     nonce,
+	}
+	if len(userIDs) == 0 {
+		data.Query = &query
+	}
+
+	if err := c.send(ctx, gatewayCommand{Op: opRequestGuildMembers, D: data}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-req.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-closed:
+		return nil, errWriterStopped
+	}
+
+	c.requestsMu.Lock()
+	defer c.requestsMu.Unlock()
+
+	return req.members, nil
+}
+
+// receiveChunk adds a GUILD_MEMBERS_CHUNK to the request it answers, if any
+func (c *Client) receiveChunk(data json.RawMessage) {
+	var chunk GuildMembersChunk
+	if err := json.Unmarshal(data, &chunk); err != nil || chunk.Nonce == "" {
+		return
+	}
+
+	c.requestsMu.Lock()
+	defer c.requestsMu.Unlock()
+
+	req, ok := c.memberRequests[chunk.Nonce]
+	if !ok {
+		return
+	}
+
+	req.members = append(req.members, chunk.Members...)
+	if req.received++; req.received >= chunk.ChunkCount {
+		delete(c.memberRequests, chunk.Nonce)
+		close(req.done)
+	}
+}
@@ -0,0 +1,105 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRequestGuildMembers(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client()
+	conn, listening := g.connect(t, c)
+
+	type result struct {
+		members []GuildMember
+		err     error
+	}
+	requested := make(chan result, 1)
+	go func() {
+		members, err := c.RequestGuildMembers(context.Background(), "1", "", 0, nil, false)
+		requested <- result{members, err}
+	}()
+
+	var data requestGuildMembersData
+	conn.expect(opRequestGuildMembers, &data)
+	if data.GuildID != "1" || data.Query == nil || *data.Query != "" || data.Nonce == "" {
+		t.Fatalf("got request %+v", data)
+	}
+
+	chunk := func(nonce string, index int, userID string) {
+		conn.send(opDispatch, GatewayGuildMembersChunk, 2, GuildMembersChunk{
+			GuildID:    "1",
+			Members:    []GuildMember{{User: User{ID: userID}}},
+			ChunkIndex: index,
+			ChunkCount: 2,
+			Nonce:      nonce,
+		})
+	}
+	chunk("other", 0, "5")
+	chunk(data.Nonce, 0, "2")
+
+	select {
+	case r := <-requested:
+		t.Fatalf("request finished after one of two chunks with %+v, %v", r.members, r.err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	chunk(data.Nonce, 1, "3")
+	r := <-requested
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if len(r.members) != 2 || r.members[0].ID != "2" || r.members[1].ID != "3" {
+		t.Errorf("got members %+v", r.members)
+	}
+
+	// A late chunk for a finished request is ignored
+	chunk(data.Nonce, 1, "4")
+
+	_ = c.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestGuildMembersInHandler(t *testing.T) {
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	c := g.client()
+	failOnError(t, c)
+
+	requested := make(chan []GuildMember, 1)
+	c.HandleOnce(GatewayGuildCreate, EventHandlerFunc(func(client *Client, _ json.RawMessage) error {
+		members, err := client.RequestGuildMembers(context.Background(), "1", "", 0, nil, false)
+		requested <- members
+		return err
+	}))
+	conn, listening := g.connect(t, c)
+
+	conn.send(opDispatch, GatewayGuildCreate, 2, map[string]string{"id": "1"})
+	var data requestGuildMembersData
+	conn.expect(opRequestGuildMembers, &data)
+	conn.send(opDispatch, GatewayGuildMembersChunk, 3, GuildMembersChunk{
+		GuildID:    "1",
+		Members:    []GuildMember{{User: User{ID: "2"}}},
+		ChunkCount: 1,
+		Nonce:      data.Nonce,
+	})
+
+	select {
+	case members := <-requested:
+		if len(members) != 1 || members[0].ID != "2" {
+			t.Errorf("got members %+v", members)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("RequestGuildMembers did not return in a handler")
+	}
+
+	_ = c.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
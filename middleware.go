@@ -0,0 +1,72 @@
+package discord
+
+import "encoding/json"
+
+// Middleware wraps an EventHandler, to act before or after it or to skip it. See Client.Use.
+type Middleware func(next EventHandler) EventHandler
+
+// IgnoreBots is Middleware that skips events sent by bots, such as messages with a bot
+// author and reactions by a bot user.
+func IgnoreBots(next EventHandler) EventHandler {
+	return EventHandlerFunc(func(client *Client, data json.RawMessage) error {
+		var event struct {
+			Author *User `json:"author"`
+			User   *User `json:"user"`
+			Member *struct {
+				User *User `json:"user"`
+			} `json:"member"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return next.Handle(client, data)
+		}
+
+		user := event.Author
+		if user == nil {
+			user = event.User
+		}
+		if user == nil && event.Member != nil {
+			user = event.Member.User
+		}
+		if user != nil && user.Bot {
+			return nil
+		}
+		return next.Handle(client, data)
+	})
+}
+
+// OnlyGuilds returns Middleware that skips events for guilds other than guildIDs. Events
+// that are not for a guild, such as READY and direct messages, are still handled.
+func OnlyGuilds(guildIDs ...string) Middleware {
+	allowed := make(map[string]bool, len(guildIDs))
+	for _, id := range guildIDs {
+		allowed[id] = true
+	}
+
+	return func(next EventHandler) EventHandler {
+		return EventHandlerFunc(func(client *Client, data json.RawMessage) error {
+			if id := eventGuildID(data); id != "" && !allowed[id] {
+				return nil
+			}
+			return next.Handle(client, data)
+		})
+	}
+}
+
+// eventGuildID returns the ID of the guild an event is for, or "" if it is not for a guild.
+// The guilds of GUILD_CREATE, GUILD_UPDATE and GUILD_DELETE have it as their ID rather than
+// a guild_id, and are told apart by fields only guilds have.
+func eventGuildID(data json.RawMessage) string {
+	var event struct {
+		ID          string  `json:"id"`
+		GuildID     string  `json:"guild_id"`
+		Region      *string `json:"region"`
+		Unavailable *bool   `json:"unavailable"`
+	}
+	if json.Unmarshal(data, &event) != nil {
+		return ""
+	}
+	if event.GuildID == "" && (event.Region != nil || event.Unavailable != nil) {
+		return event.ID
+	}
+	return event.GuildID
+}
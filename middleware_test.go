@@ -0,0 +1,60 @@
+package discord
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	c := NewClient("")
+	failOnError(t, c)
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next EventHandler) EventHandler {
+			return EventHandlerFunc(func(client *Client, data json.RawMessage) error {
+				calls = append(calls, name)
+				return next.Handle(client, data)
+			})
+		}
+	}
+
+	c.Handle(GatewayMessageCreate, MessageHandler(func(_ *Client, m Message) {
+		calls = append(calls, m.Content)
+	}))
+	c.Use(trace("outer"), trace("inner"), IgnoreBots, OnlyGuilds("1"))
+
+	for _, data := range []string{
+		`{"guild_id":"1","author":{"bot":false},"content":"guild 1"}`,
+		`{"guild_id":"2","author":{"bot":false},"content":"guild 2"}`,
+		`{"guild_id":"1","author":{"bot":true},"content":"bot"}`,
+		`{"author":{"bot":false},"content":"direct message"}`,
+	} {
+		handle(GatewayMessageCreate, c, json.RawMessage(data))
+	}
+
+	want := []string{
+		"outer", "inner", "guild 1",
+		"outer", "inner",
+		"outer", "inner",
+		"outer", "inner", "direct message",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("got %q, want %q", calls, want)
+	}
+}
+
+func TestEventGuildID(t *testing.T) {
+	tests := map[string]string{
+		`{"id":"5","guild_id":"1"}`:         "1",
+		`{"id":"1","name":"g","region":""}`: "1",
+		`{"id":"1","unavailable":true}`:     "1",
+		`{"id":"5","type":1}`:               "",
+	}
+	for data, want := range tests {
+		if got := eventGuildID(json.RawMessage(data)); got != want {
+			t.Errorf("%s: got %q, want %q", data, got, want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package discord
 
+import "encoding/json"
+
 type GatewayEventType string
 
 // Gateway operations
@@ -7,6 +9,7 @@ const (
 	GatewayHello                    GatewayEventType = "HELLO"
 	GatewayReady                    GatewayEventType = "READY"
 	GatewayResume                   GatewayEventType = "RESUME"
+	GatewayResumed                  GatewayEventType = "RESUMED"
 	GatewayChannelCreate            GatewayEventType = "CHANNEL_CREATE"
 	GatewayChannelUpdate            GatewayEventType = "CHANNEL_UPDATE"
 	GatewayChannelDelete            GatewayEventType = "CHANNEL_DELETE"
@@ -40,13 +43,36 @@ const (
 	GatewayWebhooksUpdate           GatewayEventType = "WEBHOOKS_UPDATE"
 )
 
+// Gateway opcodes
+const (
+	opDispatch            = 0
+	opHeartbeat           = 1
+	opIdentify            = 2
+	opStatusUpdate        = 3
+	opVoiceStateUpdate    = 4
+	opResume              = 6
+	opReconnect           = 7
+	opRequestGuildMembers = 8
+	opInvalidSession      = 9
+	opHello               = 10
+	opHeartbeatACK        = 11
+)
+
+// gatewayOp is a payload received from the gateway. D is left undecoded until the
+// type it decodes into is known.
 type gatewayOp struct {
 	Op int              `json:"op"`
-	D  interface{}      `json:"d"`
+	D  json.RawMessage  `json:"d"`
 	S  int              `json:"s"`
 	T  GatewayEventType `json:"t"`
 }
 
+// gatewayCommand is a payload sent to the gateway
+type gatewayCommand struct {
+	Op int         `json:"op"`
+	D  interface{} `json:"d"`
+}
+
 type helloData struct {
 	HeartbeatInterval int `json:"heartbeat_interval"`
 }
@@ -58,6 +84,9 @@ type identifyData struct {
 		Browser string `json:"$browser"`
 		Device  string `json:"$device"`
 	} `json:"properties"`
+	Intents  Intents           `json:"intents,omitempty"`
+	Shard    *[2]int           `json:"shard,omitempty"`
+	Presence *statusUpdateData `json:"presence,omitempty"`
 }
 
 type resumeData struct {
@@ -66,16 +95,9 @@ type resumeData struct {
 	Sequence  int    `json:"seq"`
 }
 
-type dispatchData struct {
-	D struct {
-		SessionID string `json:"session_id"`
-		User      User   `json:"user"`
-	} `json:"d"`
-}
-
 type GuildMemberUpdate struct {
-	GuildID string `json:"guild_id"`
-	Roles   []Role `json:"roles"`
-	User    User   `json:"user"`
-	Nick    string `json:"nick"`
+	GuildID string   `json:"guild_id"`
+	Roles   []string `json:"roles"`
+	User    User     `json:"user"`
+	Nick    string   `json:"nick"`
 }
@@ -0,0 +1,200 @@
+package discord
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How many times a request is retried after a 429 Too Many Requests
+const maxRateLimitRetries = 3
+
+// rateLimiter holds REST requests to Discord's rate limits. Discord puts routes in buckets,
+// which it names in the X-RateLimit-Bucket header, and limits each bucket separately for
+// each channel, guild or webhook. Requests in the same bucket are made one at a time.
+type rateLimiter struct {
+	mu          sync.Mutex
+	hashes      map[string]string // Route to bucket hash
+	buckets     map[string]*bucket
+	globalUntil time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		hashes:  map[string]string{},
+		buckets: map[string]*bucket{},
+	}
+}
+
+// bucket is the rate limit state of a bucket
+type bucket struct {
+	sem       chan struct{} // Held while a request is made
+	remaining int
+	reset     time.Time
+}
+
+// route identifies a REST route, such as "POST /channels/:id/messages", and its major
+// parameter, which is the ID of the channel, guild or webhook it is for
+type route struct {
+	key   string
+	major string
+}
+
+// routeOf returns the route of a request. IDs in the path are replaced by placeholders,
+// except for the major parameter, which is returned separately.
+func routeOf(method, path string) route {
+	r := route{}
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		switch prev := segments[i-1]; {
+		case (prev == "channels" || prev == "guilds" || prev == "webhooks") && r.major == "":
+			r.major = segments[i]
+			segments[i] = ":major"
+		case prev == "reactions":
+			segments[i] = ":emoji"
+		case isSnowflake(segments[i]):
+			segments[i] = ":id"
+		}
+	}
+	r.key = method + " " + strings.Join(segments, "/")
+	return r
+}
+
+// isSnowflake reports whether s looks like a Discord ID
+func isSnowflake(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// bucket returns the bucket for r. Until Discord has named the route's bucket, the route
+// is its own bucket.
+func (l *rateLimiter) bucket(r route) *bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := r.key
+	if hash, ok := l.hashes[r.key]; ok {
+		key = hash
+	}
+	key += ":" + r.major
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{sem: make(chan struct{}, 1), remaining: 1}
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// do makes req with client once its bucket and the global rate limit allow it. Requests
+// that are rate limited anyway are retried if their body can be sent again.
+func (l *rateLimiter) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	r := routeOf(req.Method, req.URL.Path)
+	b := l.bucket(r)
+
+	select {
+	case b.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-b.sem }()
+
+	for retries := 0; ; retries++ {
+		if err := l.wait(ctx, b); err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		l.update(r, b, resp)
+
+		if resp.StatusCode != http.StatusTooManyRequests || retries == maxRateLimitRetries {
+			return resp, nil
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			if req.Body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+		resp.Body.Close()
+	}
+}
+
+// wait blocks until neither b nor the global rate limit is exhausted
+func (l *rateLimiter) wait(ctx context.Context, b *bucket) error {
+	l.mu.Lock()
+	until := l.globalUntil
+	if b.remaining <= 0 && b.reset.After(until) {
+		until = b.reset
+	}
+	l.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// update records the rate limit headers of a response to a request in b
+func (l *rateLimiter) update(r route, b *bucket, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	h := resp.Header
+
+	if hash := h.Get("X-RateLimit-Bucket"); hash != "" && l.hashes[r.key] != hash {
+		l.hashes[r.key] = hash
+		if _, ok := l.buckets[hash+":"+r.major]; !ok {
+			l.buckets[hash+":"+r.major] = b
+		}
+	}
+
+	if remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		b.remaining = remaining
+	} else if b.reset.Before(now) {
+		// Routes without a rate limit never run out
+		b.remaining = 1
+	}
+	if resetAfter, err := strconv.ParseFloat(h.Get("X-RateLimit-Reset-After"), 64); err == nil {
+		b.reset = now.Add(time.Duration(resetAfter * float64(time.Second)))
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, err := strconv.ParseFloat(h.Get("Retry-After"), 64)
+		if err != nil {
+			retryAfter = 1
+		}
+		until := now.Add(time.Duration(retryAfter * float64(time.Second)))
+
+		if h.Get("X-RateLimit-Global") == "true" {
+			l.globalUntil = until
+		} else {
+			b.remaining = 0
+			b.reset = until
+		}
+	}
+}
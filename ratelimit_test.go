@@ -0,0 +1,72 @@
+package discord
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRouteOf(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         route
+	}{
+		{"POST", "/api/v6/channels/123/messages", route{"POST /api/v6/channels/:major/messages", "123"}},
+		{"DELETE", "/api/v6/channels/123/messages/456", route{"DELETE /api/v6/channels/:major/messages/:id", "123"}},
+		{"PUT", "/api/v6/channels/123/messages/456/reactions/%F0%9F%91%8D/@me", route{"PUT /api/v6/channels/:major/messages/:id/reactions/:emoji/@me", "123"}},
+		{"GET", "/api/v6/users/789", route{"GET /api/v6/users/:id", ""}},
+	}
+	for _, test := range tests {
+		if got := routeOf(test.method, test.path); got != test.want {
+			t.Errorf("%s %s: got %+v, want %+v", test.method, test.path, got, test.want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Bucket", "abc")
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			// The bucket is exhausted for a moment
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset-After", "0.2")
+		case 2:
+			// Rate limited anyway, so the request is retried
+			w.Header().Set("Retry-After", "0.2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		default:
+			w.Header().Set("X-RateLimit-Remaining", "4")
+		}
+	}))
+	defer srv.Close()
+
+	l := newRateLimiter()
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/channels/1/messages", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := l.do(srv.Client(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d", resp.StatusCode)
+		}
+	}
+
+	if requests != 3 {
+		t.Fatalf("got %d requests, want 3", requests)
+	}
+	if d := time.Since(start); d < 400*time.Millisecond {
+		t.Fatalf("requests took %v, want at least 400ms", d)
+	}
+}
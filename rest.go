@@ -0,0 +1,200 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// REST API defaults
+const (
+	defaultAPIURL     = "https://discordapp.com/api"
+	defaultAPIVersion = 6
+	defaultUserAgent  = "DiscordBot (https://github.com/miniriley2012/discord)"
+)
+
+// AuthScheme is how a client's token authorizes REST requests.
+type AuthScheme string
+
+// Authorization schemes
+const (
+	AuthBot    AuthScheme = "Bot"    // A bot token. This is the default.
+	AuthBearer AuthScheme = "Bearer" // An OAuth2 access token
+)
+
+// WithAPIURL makes the client send REST requests to url, such as a proxy or a stub server,
+// instead of https://discordapp.com/api. The API version is appended to it.
+func WithAPIURL(url string) ClientOption {
+	return func(c *Client) {
+		c.apiURL = url
+	}
+}
+
+// WithAPIVersion makes the client use version of the REST API. The default is 6.
+func WithAPIVersion(version int) ClientOption {
+	return func(c *Client) {
+		c.apiVersion = version
+	}
+}
+
+// WithHTTPClient makes the client send REST requests with client instead of http.DefaultClient.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header of REST requests. Discord asks for it to be
+// of the form "DiscordBot ($url, $versionNumber)".
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithAuthScheme sets how the client's token authorizes REST requests.
+func WithAuthScheme(scheme AuthScheme) ClientOption {
+	return func(c *Client) {
+		c.authScheme = scheme
+	}
+}
+
+// newRequest creates a REST request to path, which is relative to the versioned API URL
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	url := c.apiURL + "/v" + strconv.Itoa(c.apiVersion) + path
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Authorization", string(c.authScheme)+" "+c.Token)
+	req.Header.Set("User-Agent", c.userAgent)
+	return req, nil
+}
+
+// do makes a REST request, within Discord's rate limits
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return c.rateLimits.do(c.httpClient, req)
+}
+
+// request makes a REST request to path. body, unless it is nil, is sent as JSON, and the
+// response is decoded into v unless it is nil.
+func (c *Client) request(ctx context.Context, method, path string, body, v interface{}) error {
+	r := io.Reader(http.NoBody)
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := c.newRequest(ctx, method, path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return readResponse(resp, v)
+}
+
+// requestFiles makes a multipart REST request to path that uploads files along with
+// payload, which is sent as JSON. The body is streamed as it is sent, so it is not
+// retried if Discord rate limits it. The response is decoded into v unless it is nil.
+func (c *Client) requestFiles(ctx context.Context, method, path string, payload interface{}, files []File, v interface{}) error {
+	for _, file := range files {
+		if file.Reader == nil {
+			return fmt.Errorf("discord: file %q has no Reader", file.Name)
+		}
+	}
+
+	r, w := io.Pipe()
+	defer r.Close()
+
+	mw := multipart.NewWriter(w)
+	go func() {
+		w.CloseWithError(writeMultipart(mw, payload, files))
+	}()
+
+	req, err := c.newRequest(ctx, method, path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return readResponse(resp, v)
+}
+
+// Escapes quotes in the parameters of a Content-Disposition header
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeMultipart writes payload as the payload_json part, followed by a part for each file
+func writeMultipart(w *multipart.Writer, payload interface{}, files []File) error {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="payload_json"`)
+	h.Set("Content-Type", "application/json")
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if err = json.NewEncoder(part).Encode(payload); err != nil {
+		return err
+	}
+
+	for i, file := range files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[%d]"; filename="%s"`, i, quoteEscaper.Replace(file.Name)))
+		h.Set("Content-Type", contentType)
+		if part, err = w.CreatePart(h); err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, file.Reader); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// readResponse decodes a successful response into v, if v is not nil, or returns an
+// *APIError for an unsuccessful one
+func readResponse(resp *http.Response, v interface{}) error {
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if json.NewDecoder(resp.Body).Decode(apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,186 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestREST(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.RequestURI())
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("got Authorization %q", auth)
+		}
+		if ua := r.Header.Get("User-Agent"); ua != "DiscordBot (test, 1)" {
+			t.Errorf("got User-Agent %q", ua)
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v7/channels/1":
+			_, _ = w.Write([]byte(`{"id":"1","name":"general"}`))
+		case "POST /api/v7/channels/1/messages":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["content"] != "hi" {
+				t.Errorf("got body %v, %v", body, err)
+			}
+			_, _ = w.Write([]byte(`{"id":"2","content":"hi"}`))
+		case "GET /api/v7/channels/1/messages":
+			_, _ = w.Write([]byte(`[{"id":"2","content":"hi"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Unknown Channel","code":10003}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient("token",
+		WithAPIURL(srv.URL+"/api"),
+		WithAPIVersion(7),
+		WithHTTPClient(srv.Client()),
+		WithUserAgent("DiscordBot (test, 1)"),
+		WithAuthScheme(AuthBearer),
+	)
+
+	channel, err := c.GetChannel("1")
+	if err != nil || channel.Name != "general" {
+		t.Fatal(channel, err)
+	}
+	if err = channel.Send("hi"); err != nil {
+		t.Fatal(err)
+	}
+	messages, err := channel.Messages(10)
+	if err != nil || len(messages) != 1 || messages[0].Content != "hi" {
+		t.Fatal(messages, err)
+	}
+	if _, err = c.GetChannel("3"); !IsNotFound(err) {
+		t.Fatalf("got %v for an unknown channel", err)
+	}
+
+	want := []string{
+		"GET /api/v7/channels/1",
+		"POST /api/v7/channels/1/messages",
+		"GET /api/v7/channels/1/messages?limit=10",
+		"GET /api/v7/channels/3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got requests %q, want %q", got, want)
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{"id":"3","channel_id":"1","content":"hi","message_reference":{"message_id":"2"}}`))
+	}))
+	defer srv.Close()
+
+	title := "Title"
+	message := MessageSend{
+		Content:          "hi",
+		Embeds:           []Embed{{Title: &title, Fields: []EmbedField{{Name: "a", Value: "b"}}}},
+		Nonce:            "42",
+		AllowedMentions:  &AllowedMentions{Users: []string{"5"}},
+		MessageReference: &MessageReference{MessageID: "2"},
+	}
+
+	for _, version := range []int{6, 8} {
+		c := NewClient("token", WithAPIURL(srv.URL), WithAPIVersion(version))
+		channel := Channel{ID: "1", client: c}
+
+		m, err := channel.SendMessage(context.Background(), message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ID != "3" || m.MessageReference == nil || m.MessageReference.MessageID != "2" {
+			t.Fatalf("got %+v", m)
+		}
+
+		want := map[string]interface{}{
+			"content":           "hi",
+			"nonce":             "42",
+			"allowed_mentions":  map[string]interface{}{"users": []interface{}{"5"}},
+			"message_reference": map[string]interface{}{"message_id": "2"},
+		}
+		embed := map[string]interface{}{
+			"title":  "Title",
+			"fields": []interface{}{map[string]interface{}{"name": "a", "value": "b"}},
+		}
+		if version < 8 {
+			want["embed"] = embed
+		} else {
+			want["embeds"] = []interface{}{embed}
+		}
+		if !reflect.DeepEqual(body, want) {
+			t.Fatalf("v%d: got %v, want %v", version, body, want)
+		}
+	}
+}
+
+func TestSendFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The body is streamed, so its length is unknown
+		if r.ContentLength != -1 {
+			t.Errorf("got Content-Length %d", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			return
+		}
+		if payload := r.FormValue("payload_json"); payload != `{"content":"cats"}`+"\n" {
+			t.Errorf("got payload_json %q", payload)
+		}
+
+		var attachments []string
+		for i := 0; i < 2; i++ {
+			f, h, err := r.FormFile(fmt.Sprintf("files[%d]", i))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			b, _ := ioutil.ReadAll(f)
+			attachments = append(attachments, fmt.Sprintf(`{"id":"%d","filename":%q,"size":%d,"content_type":%q}`,
+				i, h.Filename, len(b), h.Header.Get("Content-Type")))
+		}
+		_, _ = fmt.Fprintf(w, `{"id":"3","content":"cats","attachments":[%s]}`, strings.Join(attachments, ","))
+	}))
+	defer srv.Close()
+
+	c := NewClient("token", WithAPIURL(srv.URL))
+	channel := Channel{ID: "1", client: c}
+
+	m, err := channel.SendMessage(context.Background(), MessageSend{
+		Content: "cats",
+		Files: []File{
+			{Name: "cat.png", ContentType: "image/png", Reader: io.MultiReader(strings.NewReader("meow"))},
+			{Name: `"quoted".txt`, Reader: strings.NewReader("purr!")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Attachment{
+		{ID: "0", Filename: "cat.png", Size: 4, ContentType: "image/png"},
+		{ID: "1", Filename: `"quoted".txt`, Size: 5, ContentType: "application/octet-stream"},
+	}
+	if !reflect.DeepEqual(m.Attachments, want) {
+		t.Fatalf("got %+v, want %+v", m.Attachments, want)
+	}
+
+	if _, err = channel.SendMessage(context.Background(), MessageSend{Files: []File{{Name: "empty.txt"}}}); err == nil {
+		t.Fatal("sent a file with no Reader")
+	}
+}
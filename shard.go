@@ -0,0 +1,349 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How long Discord requires between identifies in the same max_concurrency bucket
+var identifyInterval = 5 * time.Second
+
+// gatewayBot is the response from the Get Gateway Bot endpoint
+type gatewayBot struct {
+	URL               string `json:"url"`
+	Shards            int    `json:"shards"`
+	SessionStartLimit struct {
+		Total          int `json:"total"`
+		Remaining      int `json:"remaining"`
+		ResetAfter     int `json:"reset_after"`
+		MaxConcurrency int `json:"max_concurrency"`
+	} `json:"session_start_limit"`
+}
+
+// getGatewayBot fetches the gateway URL and recommended shard count
+func (c *Client) getGatewayBot(ctx context.Context) (g gatewayBot, err error) {
+	err = c.request(ctx, http.MethodGet, "/gateway/bot", nil, &g)
+	return
+}
+
+// withShard makes a client identify as shard id of count
+func withShard(id, count int) ClientOption {
+	return func(c *Client) {
+		c.shard = &[2]int{id, count}
+	}
+}
+
+// withGatewayURL makes a client connect to url instead of the default gateway
+func withGatewayURL(url string) ClientOption {
+	return func(c *Client) {
+		c.gatewayURL = url
+	}
+}
+
+// ShardManager runs a bot over several gateway connections, or shards, which share
+// the same handlers. Handlers can tell shards apart with Client.Shard.
+type ShardManager struct {
+	token    string
+	options  []ClientOption
+	handlers *handlerSet
+	rest     *Client // Makes the manager's own REST requests
+
+	identifyMu sync.Mutex
+	identified map[int]time.Time // When each max_concurrency bucket last identified, or will
+
+	mu           sync.Mutex
+	gateway      gatewayBot
+	shards       []*Client
+	listenCtx    context.Context
+	cancelListen context.CancelFunc
+	errc         chan error
+	wg           sync.WaitGroup
+}
+
+// NewShardManager creates a ShardManager. Every shard is created with options.
+func NewShardManager(token string, options ...ClientOption) *ShardManager {
+	return &ShardManager{
+		token:    token,
+		options:  options,
+		handlers: newHandlerSet(),
+		rest:     NewClient(token, options...),
+	}
+}
+
+// Handle registers an EventHandler for eventType on every shard. It returns a func that
+// removes the handler.
+func (m *ShardManager) Handle(eventType GatewayEventType, handler EventHandler) (remove func()) {
+	return m.handlers.add(eventType, handler, false)
+}
+
+// HandleOnce registers an EventHandler that is removed after handling the next event of
+// eventType on any shard.
+func (m *ShardManager) HandleOnce(eventType GatewayEventType, handler EventHandler) (remove func()) {
+	return m.handlers.add(eventType, handler, true)
+}
+
+// Use adds middleware that wraps every handler registered with Handle or HandleOnce on
+// every shard.
+func (m *ShardManager) Use(middleware ...Middleware) {
+	m.handlers.use(middleware...)
+}
+
+// OnError sets the ErrorHandler for errors returned by handlers on every shard.
+func (m *ShardManager) OnError(handler ErrorHandler) {
+	m.handlers.setErrorHandler(handler)
+}
+
+// HandleAll registers a DispatchHandler for every event on every shard. It returns a func
+// that removes the handler.
+func (m *ShardManager) HandleAll(handler DispatchHandler) (remove func()) {
+	return m.handlers.addDispatch(handler)
+}
+
+// Shards returns the manager's shards, indexed by shard ID.
+func (m *ShardManager) Shards() []*Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Client(nil), m.shards...)
+}
+
+// newShard creates the client for shard id. Each time it identifies, including after
+// reconnecting, it first waits for its max_concurrency bucket.
+func (m *ShardManager) newShard(id int) *Client {
+	m.mu.Lock()
+	gateway := m.gateway
+	m.mu.Unlock()
+
+	options := []ClientOption{withShard(id, gateway.Shards)}
+	if gateway.URL != "" {
+		options = append(options, withGatewayURL(gateway.URL))
+	}
+	options = append(options, m.options...)
+
+	// Discord's REST rate limits apply to the bot, not to each shard
+	c := NewClient(m.token, options...)
+	c.handlers = m.handlers
+	c.rateLimits = m.rest.rateLimits
+	c.beforeIdentify = func(ctx context.Context) error {
+		return m.awaitIdentify(ctx, id)
+	}
+	return c
+}
+
+// Connect connects every shard to the Discord Gateway.
+func (m *ShardManager) Connect() error {
+	return m.ConnectContext(context.Background())
+}
+
+// ConnectContext fetches the recommended number of shards from Discord and connects them.
+// Shards connect in groups no larger than Discord's max_concurrency, and wait to identify
+// as Discord requires.
+func (m *ShardManager) ConnectContext(ctx context.Context) error {
+	gateway, err := m.rest.getGatewayBot(ctx)
+	if err != nil {
+		return err
+	}
+	if gateway.Shards < 1 {
+		gateway.Shards = 1
+	}
+	if gateway.SessionStartLimit.Remaining < gateway.Shards {
+		return fmt.Errorf("discord: %d session starts remaining, need %d", gateway.SessionStartLimit.Remaining, gateway.Shards)
+	}
+
+	concurrency := gateway.SessionStartLimit.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	m.mu.Lock()
+	m.gateway = gateway
+	m.mu.Unlock()
+
+	m.identifyMu.Lock()
+	m.identified = make(map[int]time.Time, concurrency)
+	m.identifyMu.Unlock()
+
+	shards := make([]*Client, gateway.Shards)
+	for i := range shards {
+		shards[i] = m.newShard(i)
+	}
+
+	for start := 0; start < len(shards); start += concurrency {
+		end := start + concurrency
+		if end > len(shards) {
+			end = len(shards)
+		}
+
+		errs := make(chan error, end-start)
+		for _, shard := range shards[start:end] {
+			go func(shard *Client) {
+				errs <- shard.ConnectContext(ctx)
+			}(shard)
+		}
+
+		for range shards[start:end] {
+			if e := <-errs; e != nil && err == nil {
+				err = e
+			}
+		}
+		if err != nil {
+			closeShards(shards[:end])
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.shards = shards
+	m.mu.Unlock()
+	return nil
+}
+
+// identifyBucket returns the max_concurrency bucket shard id identifies in
+func (m *ShardManager) identifyBucket(id int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.gateway.SessionStartLimit.MaxConcurrency < 1 {
+		return 0
+	}
+	return id % m.gateway.SessionStartLimit.MaxConcurrency
+}
+
+// awaitIdentify waits until shard id may identify without breaking Discord's limit on
+// identifies in its max_concurrency bucket. The identify's turn is taken before waiting,
+// so shards waiting in the same bucket identify one interval apart.
+func (m *ShardManager) awaitIdentify(ctx context.Context, id int) error {
+	bucket := m.identifyBucket(id)
+
+	m.identifyMu.Lock()
+	at := time.Now()
+	if next := m.identified[bucket].Add(identifyInterval); next.After(at) {
+		at = next
+	}
+	m.identified[bucket] = at
+	m.identifyMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(at)):
+		return nil
+	}
+}
+
+// closeShards closes every shard in shards
+func closeShards(shards []*Client) {
+	for _, shard := range shards {
+		_ = shard.Close()
+	}
+}
+
+// Listen listens for events on every shard. It returns once the manager is closed, or
+// when a shard stops with an error, in which case every shard is closed.
+func (m *ShardManager) Listen() error {
+	return m.ListenContext(context.Background())
+}
+
+// ListenContext is like Listen, but closes every shard and returns ctx.Err() once ctx is done.
+func (m *ShardManager) ListenContext(ctx context.Context) error {
+	listenCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.mu.Lock()
+	m.listenCtx = listenCtx
+	m.cancelListen = cancel
+	m.errc = make(chan error, 1)
+	for _, shard := range m.shards {
+		m.listen(shard)
+	}
+	errc := m.errc
+	m.mu.Unlock()
+
+	var err error
+	select {
+	case err = <-errc:
+		cancel()
+	case <-listenCtx.Done():
+	}
+
+	m.mu.Lock()
+	m.listenCtx = nil
+	m.cancelListen = nil
+	m.mu.Unlock()
+
+	m.wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// listen starts a shard listening. m.mu must be held.
+func (m *ShardManager) listen(shard *Client) {
+	ctx, errc := m.listenCtx, m.errc
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := shard.ListenContext(ctx); err != nil && ctx.Err() == nil {
+			select {
+			case errc <- err:
+			default:
+			}
+		}
+	}()
+}
+
+// Restart closes shard id and replaces it with a new connection and session. The new
+// session waits to identify if another shard in its max_concurrency bucket identified less
+// than 5 seconds ago.
+func (m *ShardManager) Restart(ctx context.Context, id int) error {
+	m.mu.Lock()
+	if id < 0 || id >= len(m.shards) {
+		m.mu.Unlock()
+		return fmt.Errorf("discord: no shard %d", id)
+	}
+	old := m.shards[id]
+	m.mu.Unlock()
+
+	_ = old.Close()
+
+	shard := m.newShard(id)
+	if err := shard.ConnectContext(ctx); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shards[id] = shard
+	if m.listenCtx != nil {
+		m.listen(shard)
+	}
+	return nil
+}
+
+// Close closes every shard. A running Listen returns once they have stopped.
+func (m *ShardManager) Close() error {
+	m.mu.Lock()
+	shards := m.shards
+	if m.cancelListen != nil {
+		m.cancelListen()
+	}
+	m.mu.Unlock()
+
+	var err error
+	for _, shard := range shards {
+		if e := shard.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
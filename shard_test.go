@@ -0,0 +1,106 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// identifyShard expects conn to identify, makes the session ready and returns its shard ID
+// and when it identified
+func identifyShard(conn *stubConn) (int, time.Time) {
+	var data identifyData
+	conn.expect(opIdentify, &data)
+	at := time.Now()
+	conn.send(opDispatch, GatewayReady, 1, map[string]string{"session_id": "session"})
+	if data.Shard == nil || data.Shard[1] != 4 {
+		conn.t.Fatalf("identified as shard %v, want one of 4", data.Shard)
+	}
+	return data.Shard[0], at
+}
+
+func TestShardManager(t *testing.T) {
+	defer func(interval time.Duration) { identifyInterval = interval }(identifyInterval)
+	identifyInterval = 200 * time.Millisecond
+	defer func(wait func() time.Duration) { invalidSessionWait = wait }(invalidSessionWait)
+	invalidSessionWait = func() time.Duration { return 0 }
+	const tolerance = 50 * time.Millisecond
+
+	g := newStubGateway(t, 45000)
+	defer g.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v6/gateway/bot" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"url":%q,"shards":4,"session_start_limit":{"total":1000,"remaining":999,"reset_after":0,"max_concurrency":2}}`,
+			"ws"+strings.TrimPrefix(g.URL, "http"))
+	}))
+	defer api.Close()
+
+	m := NewShardManager("token", WithAPIURL(api.URL))
+	connected := make(chan error, 1)
+	go func() { connected <- m.Connect() }()
+
+	// Shards identify two at a time
+	var ids [4]int
+	var at [4]time.Time
+	conns := map[int]*stubConn{}
+	for i := range ids {
+		conn := g.next()
+		ids[i], at[i] = identifyShard(conn)
+		conns[ids[i]] = conn
+	}
+	if err := <-connected; err != nil {
+		t.Fatal(err)
+	}
+	listening := make(chan error, 1)
+	go func() { listening <- m.Listen() }()
+	if ids[0]+ids[1] != 1 || ids[2]+ids[3] != 5 {
+		t.Errorf("shards identified in order %v, want 0 and 1 before 2 and 3", ids)
+	}
+	if d := at[2].Sub(at[1]); d < identifyInterval-tolerance {
+		t.Errorf("second group identified %v after the first", d)
+	}
+
+	// A restarted shard waits for its bucket
+	old := m.Shards()[1]
+	restarted := make(chan error, 1)
+	go func() { restarted <- m.Restart(context.Background(), 1) }()
+	id, restartedAt := identifyShard(g.next())
+	if err := <-restarted; err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Errorf("restarted shard %d, want 1", id)
+	}
+	last := at[2]
+	if ids[3] == 3 {
+		last = at[3]
+	}
+	if d := restartedAt.Sub(last); d < identifyInterval-tolerance {
+		t.Errorf("restarted shard identified %v after shard 3", d)
+	}
+	if m.Shards()[1] == old {
+		t.Error("shard 1 was not replaced")
+	}
+
+	// So does a shard identifying again on its own connection
+	conns[3].send(opInvalidSession, "", 0, false)
+	id, reidentifiedAt := identifyShard(conns[3])
+	if id != 3 {
+		t.Errorf("shard %d identified again, want 3", id)
+	}
+	if d := reidentifiedAt.Sub(restartedAt); d < identifyInterval-tolerance {
+		t.Errorf("shard 3 identified again %v after shard 1 restarted", d)
+	}
+
+	_ = m.Close()
+	if err := <-listening; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package discord
+
+import (
+	"context"
+	"time"
+)
+
+// Status is the online status shown on a presence.
+type Status string
+
+// Statuses a client can set
+const (
+	StatusOnline    Status = "online"
+	StatusIdle      Status = "idle"
+	StatusDND       Status = "dnd"
+	StatusInvisible Status = "invisible"
+)
+
+// StatusUpdate is the presence a client sets for itself.
+type StatusUpdate struct {
+	Status   Status
+	AFK      bool
+	Since    time.Time // When the client went idle, if it is
+	Activity *Activity
+}
+
+// statusUpdateData is the payload of a Status Update. Bots may only set the name,
+// type and URL of their activity.
+type statusUpdateData struct {
+	Since  *int64          `json:"since"`
+	Game   *activityUpdate `json:"game"`
+	Status Status          `json:"status"`
+	AFK    bool            `json:"afk"`
+}
+
+type activityUpdate struct {
+	Name string       `json:"name"`
+	Type ActivityType `json:"type"`
+	URL  string       `json:"url,omitempty"`
+}
+
+// data returns the payload that sets update
+func (update StatusUpdate) data() *statusUpdateData {
+	data := &statusUpdateData{
+		Status: update.Status,
+		AFK:    update.AFK,
+	}
+	if data.Status == "" {
+		data.Status = StatusOnline
+	}
+	if !update.Since.IsZero() {
+		since := update.Since.UnixNano() / int64(time.Millisecond)
+		data.Since = &since
+	}
+	if update.Activity != nil {
+		data.Game = &activityUpdate{
+			Name: update.Activity.Name,
+			Type: update.Activity.Type,
+			URL:  update.Activity.URL,
+		}
+	}
+	return data
+}
+
+// WithPresence makes the client identify with presence instead of appearing online with
+// no activity.
+func WithPresence(presence StatusUpdate) ClientOption {
+	return func(c *Client) {
+		c.presence = presence.data()
+	}
+}
+
+// UpdateStatus sets the client's presence.
+func (c *Client) UpdateStatus(ctx context.Context, update StatusUpdate) error {
+	return c.send(ctx, gatewayCommand{
+		Op: opStatusUpdate,
+		D:  update.data(),
+	})
+}
+
+// SetActivity sets the activity shown on the client's presence and marks it online.
+func (c *Client) SetActivity(ctx context.Context, activity Activity) error {
+	return c.UpdateStatus(ctx, StatusUpdate{
+		Status:   StatusOnline,
+		Activity: &activity,
+	})
+}
@@ -0,0 +1,204 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// Default size of the buffer of a channel returned by Client.Events
+const defaultEventBuffer = 100
+
+// OverflowPolicy is what happens to an event when the buffer of a channel returned by
+// Client.Events is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the buffer to have room, which holds up the client's other
+	// handlers. This is the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest
+	// OverflowDropNewest discards the event.
+	OverflowDropNewest
+)
+
+// WithEventBuffer sets the buffer size and overflow policy of channels returned by Client.Events.
+func WithEventBuffer(size int, overflow OverflowPolicy) ClientOption {
+	return func(c *Client) {
+		c.eventBuffer = size
+		c.overflow = overflow
+	}
+}
+
+// Event is an event delivered by Client.Events.
+type Event struct {
+	Type GatewayEventType
+	Data json.RawMessage // The event's JSON payload
+	// Value is the decoded payload, of the type passed to the event's handler type, such
+	// as a Message for GatewayMessageCreate. It is nil for events without a payload or if
+	// the payload could not be decoded, in which case the error is passed to the client's
+	// ErrorHandler.
+	Value interface{}
+}
+
+// The types events are decoded into for Event.Value
+var eventValueTypes = map[GatewayEventType]reflect.Type{
+	GatewayReady:                    reflect.TypeOf(Ready{}),
+	GatewayChannelCreate:            reflect.TypeOf(Channel{}),
+	GatewayChannelUpdate:            reflect.TypeOf(Channel{}),
+	GatewayChannelDelete:            reflect.TypeOf(Channel{}),
+	GatewayChannelPinsUpdate:        reflect.TypeOf(ChannelPinsUpdate{}),
+	GatewayGuildCreate:              reflect.TypeOf(Guild{}),
+	GatewayGuildUpdate:              reflect.TypeOf(Guild{}),
+	GatewayGuildDelete:              reflect.TypeOf(UnavailableGuild{}),
+	GatewayGuildBanAdd:              reflect.TypeOf(GuildBan{}),
+	GatewayGuildBanRemove:           reflect.TypeOf(GuildBan{}),
+	GatewayGuildEmojisUpdate:        reflect.TypeOf(GuildEmojisUpdate{}),
+	GatewayGuildIntegrationsUpdate:  reflect.TypeOf(GuildIntegrationsUpdate{}),
+	GatewayGuildMemberAdd:           reflect.TypeOf(GuildMemberAdd{}),
+	GatewayGuildMemberRemove:        reflect.TypeOf(GuildMemberRemove{}),
+	GatewayGuildMemberUpdate:        reflect.TypeOf(GuildMemberUpdate{}),
+	GatewayGuildMembersChunk:        reflect.TypeOf(GuildMembersChunk{}),
+	GatewayGuildRoleCreate:          reflect.TypeOf(GuildRole{}),
+	GatewayGuildRoleUpdate:          reflect.TypeOf(GuildRole{}),
+	GatewayGuildRoleDelete:          reflect.TypeOf(GuildRoleDelete{}),
+	GatewayMessageCreate:            reflect.TypeOf(Message{}),
+	GatewayMessageUpdate:            reflect.TypeOf(Message{}),
+	GatewayMessageDelete:            reflect.TypeOf(MessageDelete{}),
+	GatewayMessageDeleteBulk:        reflect.TypeOf(MessageDeleteBulk{}),
+	GatewayMessageReactionAdd:       reflect.TypeOf(MessageReaction{}),
+	GatewayMessageReactionRemove:    reflect.TypeOf(MessageReaction{}),
+	GatewayMessageReactionRemoveAll: reflect.TypeOf(MessageReactionRemoveAll{}),
+	GatewayPresenceUpdate:           reflect.TypeOf(Presence{}),
+	GatewayTypingStart:              reflect.TypeOf(TypingStart{}),
+	GatewayUserUpdate:               reflect.TypeOf(User{}),
+	GatewayVoiceStateUpdate:         reflect.TypeOf(VoiceState{}),
+	GatewayVoiceServerUpdate:        reflect.TypeOf(VoiceServerUpdate{}),
+	GatewayWebhooksUpdate:           reflect.TypeOf(WebhooksUpdate{}),
+}
+
+// newEvent decodes the value of an event received by c
+func newEvent(c *Client, eventType GatewayEventType, data json.RawMessage) (Event, error) {
+	e := Event{Type: eventType, Data: data}
+
+	t, ok := eventValueTypes[eventType]
+	if !ok {
+		return e, nil
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return e, err
+	}
+
+	e.Value = v.Elem().Interface()
+	if channel, ok := e.Value.(Channel); ok {
+		channel.client = c
+		e.Value = channel
+	}
+	return e, nil
+}
+
+// Events returns a channel that receives the events of types, or every event if no types
+// are given. The channel is closed once ctx is done or the client is closed. Its buffer
+// size and what happens when the buffer is full are set by WithEventBuffer.
+func (c *Client) Events(ctx context.Context, types ...GatewayEventType) <-chan Event {
+	size := c.eventBuffer
+	if size <= 0 {
+		size = defaultEventBuffer
+	}
+
+	s := &subscription{
+		ctx:        ctx,
+		clientDone: c.clientContext().Done(),
+		client:     c,
+		types:      make(map[GatewayEventType]bool, len(types)),
+		overflow:   c.overflow,
+		events:     make(chan Event, size),
+	}
+	for _, eventType := range types {
+		s.types[eventType] = true
+	}
+
+	remove := c.handlers.addDispatch(s.deliver)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.clientDone:
+		}
+		remove()
+		s.close()
+	}()
+	return s.events
+}
+
+// subscription delivers events to a channel returned by Client.Events
+type subscription struct {
+	ctx        context.Context
+	clientDone <-chan struct{} // Closed once the client is closed
+	client     *Client
+	types      map[GatewayEventType]bool
+	overflow   OverflowPolicy
+
+	mu     sync.Mutex
+	events chan Event
+	closed bool
+}
+
+// deliver is the DispatchHandler that sends events to the channel
+func (s *subscription) deliver(client *Client, eventType GatewayEventType, data json.RawMessage) {
+	// The handlers of a ShardManager are shared by every shard
+	if client != s.client || len(s.types) != 0 && !s.types[eventType] {
+		return
+	}
+
+	e, err := newEvent(client, eventType, data)
+	if err != nil {
+		if onError := client.handlers.errorHandler(); onError != nil {
+			onError(client, eventType, data, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	switch s.overflow {
+	case OverflowDropNewest:
+		select {
+		case s.events <- e:
+		default:
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.events <- e:
+				return
+			default:
+			}
+			select {
+			case <-s.events:
+			default:
+			}
+		}
+	default:
+		select {
+		case s.events <- e:
+		case <-s.ctx.Done():
+		case <-s.clientDone:
+		}
+	}
+}
+
+// close closes the channel once no event is being delivered
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	close(s.events)
+}
@@ -0,0 +1,41 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		overflow OverflowPolicy
+		want     []string
+	}{
+		{OverflowDropOldest, []string{"2", "3"}},
+		{OverflowDropNewest, []string{"0", "1"}},
+	}
+
+	for _, test := range tests {
+		c := NewClient("", WithEventBuffer(2, test.overflow))
+		failOnError(t, c)
+		ctx, cancel := context.WithCancel(context.Background())
+		events := c.Events(ctx, GatewayMessageCreate)
+
+		for i := 0; i < 4; i++ {
+			data := json.RawMessage(fmt.Sprintf(`{"content":"%d"}`, i))
+			handle(GatewayTypingStart, c, json.RawMessage(`{}`))
+			handle(GatewayMessageCreate, c, data)
+		}
+		cancel()
+
+		var got []string
+		for e := range events {
+			got = append(got, e.Value.(Message).Content)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("overflow %v: got %q, want %q", test.overflow, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/debug"
+	"time"
+)
+
+// watcher sees every event as soon as it is read, before it is dispatched, so it works
+// even while the client's handlers are busy, including from inside a handler
+type watcher struct {
+	watch func(eventType GatewayEventType, data json.RawMessage)
+}
+
+// addWatcher registers w and returns a func that removes it
+func (c *Client) addWatcher(w *watcher) func() {
+	c.watchersMu.Lock()
+	c.watchers[w] = true
+	c.watchersMu.Unlock()
+
+	return func() {
+		c.watchersMu.Lock()
+		delete(c.watchers, w)
+		c.watchersMu.Unlock()
+	}
+}
+
+// watch passes an event to every watcher
+func (c *Client) watch(eventType GatewayEventType, data json.RawMessage) {
+	c.watchersMu.Lock()
+	watchers := make([]*watcher, 0, len(c.watchers))
+	for w := range c.watchers {
+		watchers = append(watchers, w)
+	}
+	c.watchersMu.Unlock()
+
+	for _, w := range watchers {
+		w.watch(eventType, data)
+	}
+}
+
+// collect gathers events of eventType that match, until it has max of them if max is
+// positive, timeout passes if it is positive, ctx is done or the client is closed. Only
+// ctx and the client closing are reported as errors. A panic in match is reported to the
+// client's ErrorHandler and counts as no match.
+func (c *Client) collect(ctx context.Context, eventType GatewayEventType, max int, timeout time.Duration, match func(Event) bool) ([]Event, error) {
+	matches := make(chan Event)
+	done := make(chan struct{})
+	defer close(done)
+
+	matching := func(e Event) (ok bool) {
+		defer func() {
+			if v := recover(); v != nil {
+				if onError := c.handlers.errorHandler(); onError != nil {
+					onError(c, eventType, e.Data, &PanicError{EventType: eventType, Value: v, Stack: debug.Stack()})
+				}
+			}
+		}()
+		return match(e)
+	}
+
+	remove := c.addWatcher(&watcher{watch: func(t GatewayEventType, data json.RawMessage) {
+		if t != eventType {
+			return
+		}
+		e, err := newEvent(c, t, data)
+		if err != nil || !matching(e) {
+			return
+		}
+		select {
+		case matches <- e:
+		case <-done:
+		}
+	}})
+	defer remove()
+
+	closed := c.clientContext().Done()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var events []Event
+	for {
+		select {
+		case e := <-matches:
+			events = append(events, e)
+			if max > 0 && len(events) >= max {
+				return events, nil
+			}
+		case <-expired:
+			return events, nil
+		case <-ctx.Done():
+			return events, ctx.Err()
+		case <-closed:
+			return events, errWriterStopped
+		}
+	}
+}
+
+// WaitFor waits for the next event of eventType for which predicate returns true, which
+// may be nil to accept any event. predicate is passed the event as described by Event.
+//
+// WaitFor can be called from a handler. With DispatchSync, the client goes on reading
+// events while the handler waits, and handles them once it returns. With
+// DispatchWorkerPool, events queued behind the handler wait for it, and once its worker's
+// queue is full no more events are read, so long waits are best avoided there.
+func (c *Client) WaitFor(ctx context.Context, eventType GatewayEventType, predicate func(Event) bool) (Event, error) {
+	if predicate == nil {
+		predicate = func(Event) bool { return true }
+	}
+
+	events, err := c.collect(ctx, eventType, 1, 0, predicate)
+	if err != nil {
+		return Event{}, err
+	}
+	return events[0], nil
+}
+
+// MessageCollector gathers the messages created that match its filter.
+type MessageCollector struct {
+	Filter  func(Message) bool // Messages to collect; nil collects every message
+	Max     int                // Stop after this many messages, if positive
+	Timeout time.Duration      // Stop after this long, if positive
+}
+
+// Collect collects messages received by client until the collector's Max or Timeout is
+// reached, or ctx is done, in which case it returns the messages collected so far and
+// ctx.Err(). It can be called from a handler, as described for WaitFor.
+func (mc MessageCollector) Collect(ctx context.Context, client *Client) ([]Message, error) {
+	events, err := client.collect(ctx, GatewayMessageCreate, mc.Max, mc.Timeout, func(e Event) bool {
+		message, ok := e.Value.(Message)
+		return ok && (mc.Filter == nil || mc.Filter(message))
+	})
+
+	messages := make([]Message, len(events))
+	for i, e := range events {
+		messages[i] = e.Value.(Message)
+	}
+	return messages, err
+}
+
+// ReactionCollector gathers the reactions added that match its filter.
+type ReactionCollector struct {
+	Filter  func(MessageReaction) bool // Reactions to collect; nil collects every reaction
+	Max     int                        // Stop after this many reactions, if positive
+	Timeout time.Duration              // Stop after this long, if positive
+}
+
+// Collect collects reactions received by client until the collector's Max or Timeout is
+// reached, or ctx is done, in which case it returns the reactions collected so far and
+// ctx.Err(). It can be called from a handler, as described for WaitFor.
+func (rc ReactionCollector) Collect(ctx context.Context, client *Client) ([]MessageReaction, error) {
+	events, err := client.collect(ctx, GatewayMessageReactionAdd, rc.Max, rc.Timeout, func(e Event) bool {
+		reaction, ok := e.Value.(MessageReaction)
+		return ok && (rc.Filter == nil || rc.Filter(reaction))
+	})
+
+	reactions := make([]MessageReaction, len(events))
+	for i, e := range events {
+		reactions[i] = e.Value.(MessageReaction)
+	}
+	return reactions, err
+}
@@ -0,0 +1,175 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// watching waits until c has n watchers
+func watching(c *Client, n int) {
+	for {
+		c.watchersMu.Lock()
+		l := len(c.watchers)
+		c.watchersMu.Unlock()
+		if l == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	c := NewClient("")
+
+	result := make(chan Event)
+	go func() {
+		e, err := c.WaitFor(context.Background(), GatewayMessageCreate, func(e Event) bool {
+			return e.Value.(Message).Content == "yes"
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		result <- e
+	}()
+	watching(c, 1)
+
+	for _, data := range []string{`{"content":"no"}`, `{"content":"yes"}`} {
+		if err := c.process(gatewayOp{Op: opDispatch, T: GatewayMessageCreate, D: json.RawMessage(data)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if e := <-result; e.Value.(Message).Content != "yes" {
+		t.Fatalf("got %+v", e)
+	}
+	watching(c, 0)
+}
+
+func TestMessageCollector(t *testing.T) {
+	c := NewClient("")
+
+	collector := MessageCollector{
+		Filter: func(m Message) bool { return m.Author.ID == "1" },
+		Max:    2,
+	}
+	result := make(chan []Message)
+	go func() {
+		messages, err := collector.Collect(context.Background(), c)
+		if err != nil {
+			t.Error(err)
+		}
+		result <- messages
+	}()
+	watching(c, 1)
+
+	for _, data := range []string{
+		`{"author":{"id":"1"},"content":"a"}`,
+		`{"author":{"id":"2"},"content":"b"}`,
+		`{"author":{"id":"1"},"content":"c"}`,
+	} {
+		c.watch(GatewayMessageCreate, json.RawMessage(data))
+	}
+
+	var got []string
+	for _, m := range <-result {
+		got = append(got, m.Content)
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// The timeout ends collection without an error
+	reactions, err := ReactionCollector{Timeout: 10 * time.Millisecond}.Collect(context.Background(), c)
+	if err != nil || len(reactions) != 0 {
+		t.Fatal(reactions, err)
+	}
+}
+
+func TestWaitForInHandler(t *testing.T) {
+	c := NewClient("")
+	failOnError(t, c)
+
+	answer := make(chan string, 1)
+	c.Handle(GatewayMessageCreate, MessageHandler(func(client *Client, m Message) {
+		if m.Content != "ask" {
+			return
+		}
+		e, err := client.WaitFor(context.Background(), GatewayMessageCreate, func(e Event) bool {
+			return e.Value.(Message).Author.ID == m.Author.ID
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		answer <- e.Value.(Message).Content
+	}))
+
+	process := func(data string) {
+		if err := c.process(gatewayOp{Op: opDispatch, T: GatewayMessageCreate, D: json.RawMessage(data)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	process(`{"author":{"id":"1"},"content":"ask"}`)
+
+	// Events are still read while the handler for "ask" waits, so the answer can be read
+	watching(c, 1)
+	process(`{"author":{"id":"2"},"content":"other"}`)
+	process(`{"author":{"id":"1"},"content":"answer"}`)
+
+	select {
+	case got := <-answer:
+		if got != "answer" {
+			t.Fatalf("got %q, want %q", got, "answer")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("WaitFor did not return")
+	}
+}
+
+func TestWaitForOutsideHandler(t *testing.T) {
+	c := NewClient("")
+	failOnError(t, c)
+
+	handled := make(chan string, 2)
+	release := make(chan struct{})
+	c.Handle(GatewayMessageCreate, MessageHandler(func(_ *Client, m Message) {
+		if m.Content == "slow" {
+			<-release
+		}
+		handled <- m.Content
+	}))
+
+	process := func(data string) {
+		if err := c.process(gatewayOp{Op: opDispatch, T: GatewayMessageCreate, D: json.RawMessage(data)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	process(`{"content":"slow"}`)
+
+	// A WaitFor elsewhere sees the next event, but its handler still waits for the slow one
+	waited := make(chan error, 1)
+	go func() {
+		_, err := c.WaitFor(context.Background(), GatewayMessageCreate, nil)
+		waited <- err
+	}()
+	watching(c, 1)
+	process(`{"content":"next"}`)
+	if err := <-waited; err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case content := <-handled:
+		t.Fatalf("handled %q while the slow handler ran", content)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for _, want := range []string{"slow", "next"} {
+		if got := <-handled; got != want {
+			t.Fatalf("handled %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/websocket"
+	"time"
+)
+
+// Discord allows 120 gateway payloads per 60 seconds on a connection. Part of that
+// allowance is kept back for heartbeats, which are never delayed.
+const (
+	gatewaySendLimit  = 115
+	gatewaySendWindow = 60 * time.Second
+)
+
+// errWriterStopped is returned for payloads that could not be written before the connection closed
+var errWriterStopped = errors.New("discord: gateway connection closed")
+
+// outbound is a payload waiting to be written to the gateway
+type outbound struct {
+	messageType int
+	data        []byte
+	errc        chan error
+}
+
+// gatewayWriter is the only writer to a gateway connection. Heartbeats and close frames
+// are written ahead of other payloads, which are held to the gateway send limit.
+type gatewayWriter struct {
+	ws       *websocket.Conn
+	codec    codec
+	priority chan outbound
+	commands chan outbound
+	sent     []time.Time
+	done     chan struct{}
+}
+
+func newGatewayWriter(ws *websocket.Conn, codec codec) *gatewayWriter {
+	return &gatewayWriter{
+		ws:       ws,
+		codec:    codec,
+		priority: make(chan outbound),
+		commands: make(chan outbound),
+		done:     make(chan struct{}),
+	}
+}
+
+// run writes queued payloads until ctx is done
+func (w *gatewayWriter) run(ctx context.Context) {
+	defer close(w.done)
+	for {
+		select {
+		case p := <-w.priority:
+			w.write(p)
+			continue
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-w.priority:
+			w.write(p)
+		case p := <-w.commands:
+			if !w.wait(ctx) {
+				p.errc <- ctx.Err()
+				return
+			}
+			w.sent = append(w.sent, time.Now())
+			w.write(p)
+		}
+	}
+}
+
+// wait blocks until the send limit allows another command, writing priority payloads
+// in the meantime. It returns false if ctx is done first.
+func (w *gatewayWriter) wait(ctx context.Context) bool {
+	now := time.Now()
+	for len(w.sent) > 0 && now.Sub(w.sent[0]) >= gatewaySendWindow {
+		w.sent = w.sent[1:]
+	}
+	if len(w.sent) < gatewaySendLimit {
+		return true
+	}
+
+	timer := time.NewTimer(gatewaySendWindow - now.Sub(w.sent[0]))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case p := <-w.priority:
+			w.write(p)
+		case <-timer.C:
+			w.sent = w.sent[1:]
+			return true
+		}
+	}
+}
+
+// write writes p to the connection and reports the result to its sender
+func (w *gatewayWriter) write(p outbound) {
+	p.errc <- w.ws.WriteMessage(p.messageType, p.data)
+}
+
+// send queues data to be written and waits until it has been
+func (w *gatewayWriter) send(ctx context.Context, messageType int, data []byte, priority bool) error {
+	p := outbound{
+		messageType: messageType,
+		data:        data,
+		errc:        make(chan error, 1),
+	}
+
+	queue := w.commands
+	if priority {
+		queue = w.priority
+	}
+
+	select {
+	case queue <- p:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.done:
+		return errWriterStopped
+	}
+
+	select {
+	case err := <-p.errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.done:
+		select {
+		case err := <-p.errc:
+			return err
+		default:
+			return errWriterStopped
+		}
+	}
+}
+
+// sendOp queues a gateway payload to be written and waits until it has been
+func (w *gatewayWriter) sendOp(ctx context.Context, cmd gatewayCommand, priority bool) error {
+	b, err := w.codec.encode(cmd)
+	if err != nil {
+		return err
+	}
+	return w.send(ctx, w.codec.messageType(), b, priority)
+}
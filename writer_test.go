@@ -0,0 +1,123 @@
+package discord
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writerPair returns a writer for a connection to a test server, and the op codes of the
+// payloads the server reads in order
+func writerPair(t *testing.T) (*gatewayWriter, <-chan int, func()) {
+	ops := make(chan int, 2*gatewaySendLimit)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for {
+			var op stubOp
+			if err := ws.ReadJSON(&op); err != nil {
+				return
+			}
+			ops <- op.Op
+		}
+	}))
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newGatewayWriter(ws, jsonCodec{}), ops, func() {
+		_ = ws.Close()
+		srv.Close()
+	}
+}
+
+func TestWriterSendLimit(t *testing.T) {
+	w, ops, closeConn := writerPair(t)
+	defer closeConn()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.run(ctx)
+
+	for i := 0; i < gatewaySendLimit; i++ {
+		if err := w.sendOp(ctx, gatewayCommand{Op: opStatusUpdate}, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	held := make(chan error, 1)
+	heldCtx, stop := context.WithCancel(ctx)
+	go func() { held <- w.sendOp(heldCtx, gatewayCommand{Op: opRequestGuildMembers}, false) }()
+
+	select {
+	case err := <-held:
+		t.Fatalf("command %d was sent within the window: %v", gatewaySendLimit+1, err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Heartbeats are not held back
+	if err := w.sendOp(ctx, gatewayCommand{Op: opHeartbeat}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	stop()
+	if err := <-held; err != context.Canceled {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+
+	for i := 0; i < gatewaySendLimit; i++ {
+		if op := <-ops; op != opStatusUpdate {
+			t.Fatalf("payload %d has op %d", i, op)
+		}
+	}
+	if op := <-ops; op != opHeartbeat {
+		t.Fatalf("got op %d after the limit, want a heartbeat", op)
+	}
+}
+
+func TestWriterPriority(t *testing.T) {
+	w, ops, closeConn := writerPair(t)
+	defer closeConn()
+
+	// The window is full, and its oldest command expires shortly
+	now := time.Now()
+	w.sent = append(w.sent, now.Add(100*time.Millisecond-gatewaySendWindow))
+	for len(w.sent) < gatewaySendLimit {
+		w.sent = append(w.sent, now)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.run(ctx)
+
+	sent := make(chan error, 1)
+	go func() { sent <- w.sendOp(ctx, gatewayCommand{Op: opStatusUpdate}, false) }()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := w.sendOp(ctx, gatewayCommand{Op: opHeartbeat}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-sent; err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(now); d < 100*time.Millisecond {
+		t.Errorf("command was sent after %v, before the window allowed", d)
+	}
+
+	var got []int
+	for len(got) < 2 {
+		got = append(got, <-ops)
+	}
+	if got[0] != opHeartbeat || got[1] != opStatusUpdate {
+		t.Errorf("got ops %v, want the heartbeat ahead of the command", got)
+	}
+}
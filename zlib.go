@@ -0,0 +1,67 @@
+package discord
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// Size of the deflate window, the furthest back a compressed payload can refer
+const windowSize = 32 << 10
+
+// zlibSuffix is the Z_SYNC_FLUSH marker that ends every payload in a zlib-stream
+var zlibSuffix = []byte{0x00, 0x00, 0xff, 0xff}
+
+var errZlibHeader = errors.New("discord: invalid zlib-stream header")
+
+// inflater decompresses a zlib-stream gateway connection. The connection is compressed as
+// a single stream which is flushed after each payload, so each payload can refer back to
+// data from earlier ones. Inflating a payload on its own would lose that history, so the
+// last window of output is kept and used as the dictionary for the next payload.
+type inflater struct {
+	buf     []byte
+	window  []byte
+	started bool
+	r       io.ReadCloser
+}
+
+// inflate adds a websocket message to the stream. It returns the decompressed payload
+// once the message completing it arrives, or nil if more messages are needed.
+func (z *inflater) inflate(msg []byte) ([]byte, error) {
+	z.buf = append(z.buf, msg...)
+	if !bytes.HasSuffix(z.buf, zlibSuffix) {
+		return nil, nil
+	}
+
+	data := z.buf
+	z.buf = z.buf[:0]
+
+	if !z.started {
+		// The stream starts with a zlib header, after which it is raw deflate
+		if len(data) < 2 || data[0]&0x0f != 8 || data[1]&0x20 != 0 || (uint16(data[0])<<8|uint16(data[1]))%31 != 0 {
+			return nil, errZlibHeader
+		}
+		data = data[2:]
+		z.started = true
+	}
+
+	if z.r == nil {
+		z.r = flate.NewReaderDict(bytes.NewReader(data), z.window)
+	} else if err := z.r.(flate.Resetter).Reset(bytes.NewReader(data), z.window); err != nil {
+		return nil, err
+	}
+
+	// A flushed payload ends mid-stream, so running out of input is expected
+	out, err := ioutil.ReadAll(z.r)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	z.window = append(z.window, out...)
+	if len(z.window) > windowSize {
+		z.window = append(z.window[:0], z.window[len(z.window)-windowSize:]...)
+	}
+	return out, nil
+}
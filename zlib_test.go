@@ -0,0 +1,59 @@
+package discord
+
+import (
+	"bytes"
+	"compress/zlib"
+	"strings"
+	"testing"
+)
+
+func TestInflater(t *testing.T) {
+	payloads := []string{
+		`{"op":10,"d":{"heartbeat_interval":41250}}`,
+		`{"op":0,"t":"GUILD_CREATE","d":{"name":"` + strings.Repeat("guild ", 10000) + `"}}`,
+		`{"op":0,"t":"MESSAGE_CREATE","d":{"content":"guild guild guild"}}`,
+		`{"op":11}`,
+	}
+
+	var stream bytes.Buffer
+	w := zlib.NewWriter(&stream)
+	z := &inflater{}
+
+	for i, payload := range payloads {
+		if _, err := w.Write([]byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Split the payload over two messages to check it is only returned once complete
+		msg := stream.Bytes()
+		half := len(msg) / 2
+
+		out, err := z.inflate(append([]byte(nil), msg[:half]...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != nil {
+			t.Fatalf("payload %v: got output from incomplete message", i)
+		}
+
+		out, err = z.inflate(append([]byte(nil), msg[half:]...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != payload {
+			t.Fatalf("payload %v: got %q, want %q", i, out, payload)
+		}
+
+		stream.Reset()
+	}
+}
+
+func TestInflaterHeader(t *testing.T) {
+	z := &inflater{}
+	if _, err := z.inflate([]byte{0x00, 0x00, 0x00, 0xff, 0xff}); err != errZlibHeader {
+		t.Fatalf("got %v, want %v", err, errZlibHeader)
+	}
+}